@@ -47,5 +47,9 @@ type Adapter struct {
 	KubeconfigHandler meshkitCfg.Handler
 	Log               logger.Handler
 	EventStreamer     *events.EventStreamer
+	// TemplateResolver reads the templates of the operations applied by ApplyOperation. Defaults to DefaultTemplateResolver.
+	TemplateResolver *TemplateResolver
 	// mx                sync.Mutex
+
+	health adapterHealth
 }
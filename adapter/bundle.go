@@ -0,0 +1,306 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BundleIndexFile is the name of the index of a template bundle, see Bundle.
+const BundleIndexFile = "bundle.json"
+
+// BundleIndex lists the templates of a bundle.
+type BundleIndex struct {
+	// Version is the version of the adapter the bundle was built for.
+	Version string `json:"version,omitempty"`
+	// Created is the time the bundle was built, in RFC 3339 format.
+	Created string `json:"created,omitempty"`
+	// Templates maps the source of each template, see Template.Source, to the hex SHA-256 digest of its contents.
+	Templates map[string]string `json:"templates"`
+}
+
+// Bundle holds the contents of the templates of an adapter's operations, so that they can be applied without network
+// access, e.g. in air-gapped clusters. Set it as the Bundle of the adapter's TemplateResolver to serve the templates from it.
+//
+// A bundle is a directory, or a gzipped tar archive of it, with the index BundleIndexFile and the contents of each
+// template in sha256/<digest>, the layout of the cache of TemplateResolver. Bundles are built by BuildBundle and
+// WriteBundleArchive, or with the bundle command of adapterctl, and the directory can be embedded with embed.FS.
+type Bundle struct {
+	BundleIndex
+	read func(name string) ([]byte, error)
+}
+
+// OpenBundle opens the bundle in fsys, e.g. os.DirFS of its directory, or the sub-tree of an embed.FS holding it.
+func OpenBundle(fsys fs.FS) (*Bundle, error) {
+	b := &Bundle{read: func(name string) ([]byte, error) { return fs.ReadFile(fsys, name) }}
+	if err := b.readIndex(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// OpenBundleArchive opens the bundle in the gzipped tar archive read from r. The archive is read into memory.
+func OpenBundleArchive(r io.Reader) (*Bundle, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, ErrOpenBundle(err)
+	}
+	defer gz.Close()
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if stderrors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, ErrOpenBundle(err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, ErrOpenBundle(err)
+		}
+		files[path.Clean(hdr.Name)] = data
+	}
+
+	b := &Bundle{read: func(name string) ([]byte, error) {
+		data, ok := files[name]
+		if !ok {
+			return nil, fs.ErrNotExist
+		}
+		return data, nil
+	}}
+	if err := b.readIndex(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// OpenBundleFile opens the bundle at name, a gzipped tar archive or a directory.
+func OpenBundleFile(name string) (*Bundle, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, ErrOpenBundle(err)
+	}
+	if info.IsDir() {
+		return OpenBundle(os.DirFS(name))
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, ErrOpenBundle(err)
+	}
+	defer f.Close()
+	return OpenBundleArchive(f)
+}
+
+func (b *Bundle) readIndex() error {
+	data, err := b.read(BundleIndexFile)
+	if err != nil {
+		return ErrOpenBundle(err)
+	}
+	if err := json.Unmarshal(data, &b.BundleIndex); err != nil {
+		return ErrOpenBundle(err)
+	}
+	if b.Templates == nil {
+		b.Templates = make(map[string]string)
+	}
+	return nil
+}
+
+// lookup returns the contents of the template with the given source and checksum, if the bundle holds them intact.
+func (b *Bundle) lookup(source, checksum string) (string, bool) {
+	if checksum == "" {
+		checksum = b.Templates[source]
+	}
+	if !validChecksum(checksum) {
+		return "", false
+	}
+	data, err := b.read(path.Join("sha256", checksum))
+	if err != nil || digest(string(data)) != checksum {
+		return "", false
+	}
+	return string(data), true
+}
+
+// Missing returns the templates the bundle does not hold, or holds corrupted. Inline templates are never missing.
+func (b *Bundle) Missing(templates []Template) []Template {
+	missing := make([]Template, 0)
+	for _, t := range templates {
+		if t.IsInline() {
+			continue
+		}
+		if _, ok := b.lookup(t.Source(), t.Checksum()); !ok {
+			missing = append(missing, t)
+		}
+	}
+	return missing
+}
+
+// Verify checks that the bundle holds all templates of ops, see BundleTemplates, and fails with ErrBundleIncomplete otherwise.
+func (b *Bundle) Verify(ops Operations) error {
+	templates, err := BundleTemplates(ops)
+	if err != nil {
+		return err
+	}
+	missing := b.Missing(templates)
+	if len(missing) == 0 {
+		return nil
+	}
+	sources := make([]string, 0, len(missing))
+	for _, t := range missing {
+		sources = append(sources, string(t))
+	}
+	return ErrBundleIncomplete(sources)
+}
+
+// BundleTemplates returns the templates of ops referring to files or remote resources, sorted and without duplicates,
+// i.e. the templates to bundle. It fails with ErrUnbundleableTemplate if templates are neither URLs nor manifests,
+// e.g. the file names returned by adapters redacting the URLs of their templates, which the bundle would silently lack.
+func BundleTemplates(ops Operations) ([]Template, error) {
+	seen := make(map[Template]bool)
+	templates := make([]Template, 0)
+	var invalid []string
+	for _, op := range ops {
+		if op == nil {
+			continue
+		}
+		for _, t := range op.Templates {
+			if seen[t] {
+				continue
+			}
+			seen[t] = true
+			switch {
+			case !t.IsInline():
+				templates = append(templates, t)
+			case !isManifest(t):
+				invalid = append(invalid, string(t))
+			}
+		}
+	}
+	if len(invalid) != 0 {
+		sort.Strings(invalid)
+		return nil, ErrUnbundleableTemplate(invalid)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i] < templates[j] })
+	return templates, nil
+}
+
+// isManifest reports whether the inline template t can be a manifest, i.e. YAML or JSON with at least one key,
+// rather than a file name. NoneTemplate and empty templates stand for no template.
+func isManifest(t Template) bool {
+	s := strings.TrimSpace(string(t))
+	return s == "" || t == NoneTemplate[0] || strings.Contains(s, "\n") || strings.Contains(s, ": ") ||
+		strings.HasSuffix(s, ":") || strings.HasPrefix(s, "{") || strings.HasPrefix(s, "[")
+}
+
+// BuildBundle reads the templates with r, see BundleTemplates, and writes the bundle into the directory dir, created
+// if needed, e.g. to embed it with embed.FS. version is the version of the adapter the bundle is built for.
+func BuildBundle(ctx context.Context, r *TemplateResolver, version string, templates []Template, dir string) error {
+	index, files, err := bundleFiles(ctx, r, version, templates)
+	if err != nil {
+		return err
+	}
+	for _, name := range sortedNames(files) {
+		if err := writeFileAtomic(filepath.Join(dir, filepath.FromSlash(name)), files[name]); err != nil {
+			return ErrWriteBundle(err)
+		}
+	}
+	if err := writeFileAtomic(filepath.Join(dir, BundleIndexFile), index); err != nil {
+		return ErrWriteBundle(err)
+	}
+	return nil
+}
+
+// WriteBundleArchive reads the templates with r, see BundleTemplates, and writes the bundle to w as a gzipped tar archive.
+// version is the version of the adapter the bundle is built for.
+func WriteBundleArchive(ctx context.Context, r *TemplateResolver, version string, templates []Template, w io.Writer) error {
+	index, files, err := bundleFiles(ctx, r, version, templates)
+	if err != nil {
+		return err
+	}
+	files[BundleIndexFile] = index
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+	for _, name := range sortedNames(files) {
+		hdr := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(files[name])), ModTime: now, Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return ErrWriteBundle(err)
+		}
+		if _, err := tw.Write(files[name]); err != nil {
+			return ErrWriteBundle(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return ErrWriteBundle(err)
+	}
+	if err := gz.Close(); err != nil {
+		return ErrWriteBundle(err)
+	}
+	return nil
+}
+
+// bundleFiles reads the templates with r, and returns the index and the contents of the bundle, by file name.
+func bundleFiles(ctx context.Context, r *TemplateResolver, version string, templates []Template) ([]byte, map[string][]byte, error) {
+	index := BundleIndex{
+		Version:   version,
+		Created:   time.Now().UTC().Format(time.RFC3339),
+		Templates: make(map[string]string),
+	}
+	files := make(map[string][]byte)
+	for _, t := range templates {
+		if t.IsInline() {
+			continue
+		}
+		contents, err := r.Resolve(ctx, t)
+		if err != nil {
+			return nil, nil, err
+		}
+		checksum := digest(contents)
+		index.Templates[t.Source()] = checksum
+		files[path.Join("sha256", checksum)] = []byte(contents)
+	}
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return nil, nil, ErrWriteBundle(err)
+	}
+	return data, files, nil
+}
+
+func sortedNames(files map[string][]byte) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,254 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+)
+
+// bundleTemplates returns a remote and a local template, the contents of the local one, and the operations using them.
+func bundleTemplates(t *testing.T) ([]adapter.Template, string, adapter.Operations) {
+	srv, _ := templateServer(t)
+	local := "kind: ConfigMap\n"
+	file := filepath.Join(t.TempDir(), "configmap.yaml")
+	if err := os.WriteFile(file, []byte(local), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	templates := []adapter.Template{
+		adapter.Template(srv.URL + "/manifest.yaml" + adapter.ChecksumSeparator + sha256Hex(manifest)),
+		adapter.Template("file://" + file),
+	}
+	ops := adapter.Operations{
+		"install": {Templates: []adapter.Template{templates[0], adapter.Template(manifest)}},
+		"sample":  {Templates: []adapter.Template{templates[1], templates[0]}},
+	}
+	return templates, local, ops
+}
+
+// buildBundle builds the bundle of templates as a directory, returning its path.
+func buildBundle(t *testing.T, templates []adapter.Template) string {
+	dir := filepath.Join(t.TempDir(), "bundle")
+	if err := adapter.BuildBundle(context.Background(), &adapter.TemplateResolver{}, "v1.2.3", templates, dir); err != nil {
+		t.Fatalf("BuildBundle: %v", err)
+	}
+	return dir
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	templates, local, ops := bundleTemplates(t)
+
+	tests := []struct {
+		name  string
+		build func(t *testing.T) string
+	}{
+		{name: "directory", build: func(t *testing.T) string { return buildBundle(t, templates) }},
+		{name: "archive", build: func(t *testing.T) string {
+			var buf bytes.Buffer
+			if err := adapter.WriteBundleArchive(context.Background(), &adapter.TemplateResolver{}, "v1.2.3", templates, &buf); err != nil {
+				t.Fatalf("WriteBundleArchive: %v", err)
+			}
+			name := filepath.Join(t.TempDir(), "bundle.tar.gz")
+			if err := os.WriteFile(name, buf.Bytes(), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			return name
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := adapter.OpenBundleFile(tt.build(t))
+			if err != nil {
+				t.Fatalf("OpenBundleFile: %v", err)
+			}
+			if b.Version != "v1.2.3" {
+				t.Errorf("bundle version = %q, want %q", b.Version, "v1.2.3")
+			}
+			want := map[string]string{
+				templates[0].Source(): sha256Hex(manifest),
+				templates[1].Source(): sha256Hex(local),
+			}
+			if !reflect.DeepEqual(b.Templates, want) {
+				t.Errorf("bundle templates = %v, want %v", b.Templates, want)
+			}
+			if missing := b.Missing(templates); len(missing) != 0 {
+				t.Errorf("bundle misses %v", missing)
+			}
+			if err := b.Verify(ops); err != nil {
+				t.Errorf("Verify: %v", err)
+			}
+
+			// The templates are served from the bundle, without the network, the cache or the files.
+			file := strings.TrimPrefix(string(templates[1]), "file://")
+			if err := os.Remove(file); err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = os.WriteFile(file, []byte(local), 0o644) }()
+			r := &adapter.TemplateResolver{CacheOnly: true, Bundle: b}
+			for i, contents := range []string{manifest, local} {
+				if got, err := r.Resolve(context.Background(), templates[i]); err != nil || got != contents {
+					t.Errorf("Resolve %s from the bundle = %q, %v, want %q", templates[i], got, err, contents)
+				}
+			}
+		})
+	}
+}
+
+func TestBundleTampered(t *testing.T) {
+	templates, _, ops := bundleTemplates(t)
+	contents := filepath.Join("sha256", sha256Hex(manifest))
+
+	tests := []struct {
+		name   string
+		tamper func(t *testing.T, dir string)
+	}{
+		{name: "modified contents", tamper: func(t *testing.T, dir string) {
+			if err := os.WriteFile(filepath.Join(dir, contents), []byte("kind: Secret\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{name: "missing contents", tamper: func(t *testing.T, dir string) {
+			if err := os.Remove(filepath.Join(dir, contents)); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{name: "modified index", tamper: func(t *testing.T, dir string) {
+			var index adapter.BundleIndex
+			data, err := os.ReadFile(filepath.Join(dir, adapter.BundleIndexFile))
+			if err == nil {
+				err = json.Unmarshal(data, &index)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The pinned template is looked up by its own checksum, the index is tampered with for the unpinned one.
+			index.Templates[templates[1].Source()] = sha256Hex("kind: Secret\n")
+			if data, err = json.Marshal(index); err == nil {
+				err = os.WriteFile(filepath.Join(dir, adapter.BundleIndexFile), data, 0o644)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := buildBundle(t, templates)
+			tt.tamper(t, dir)
+			b, err := adapter.OpenBundleFile(dir)
+			if err != nil {
+				t.Fatalf("OpenBundleFile: %v", err)
+			}
+			missing := b.Missing(templates)
+			if len(missing) != 1 {
+				t.Fatalf("bundle misses %v, want one template", missing)
+			}
+			if err := b.Verify(ops); errorCode(err) != adapter.ErrBundleIncompleteCode {
+				t.Errorf("Verify error = %v, want code %s", err, adapter.ErrBundleIncompleteCode)
+			}
+			if !strings.HasPrefix(string(missing[0]), "http") {
+				// The local template is read from its file instead.
+				return
+			}
+			r := &adapter.TemplateResolver{CacheOnly: true, Bundle: b}
+			if _, err := r.Resolve(context.Background(), missing[0]); errorCode(err) != adapter.ErrTemplateNotCachedCode {
+				t.Errorf("Resolve of the tampered template error = %v, want code %s", err, adapter.ErrTemplateNotCachedCode)
+			}
+		})
+	}
+}
+
+func TestBundleTemplates(t *testing.T) {
+	const remote = "https://example.com/app.yaml"
+	tests := []struct {
+		name     string
+		ops      adapter.Operations
+		want     []adapter.Template
+		wantCode string
+	}{
+		{
+			name: "sorted without duplicates",
+			ops: adapter.Operations{
+				"a": {Templates: []adapter.Template{remote, "file:///templates/b.yaml"}},
+				"b": {Templates: []adapter.Template{"file:///templates/b.yaml", "file:///templates/a.yaml"}},
+				"c": nil,
+			},
+			want: []adapter.Template{"file:///templates/a.yaml", "file:///templates/b.yaml", remote},
+		},
+		{
+			name: "manifests and none skipped",
+			ops: adapter.Operations{
+				"a": {Templates: append([]adapter.Template{remote, adapter.Template(manifest), `{"kind": "Namespace"}`, ""}, adapter.NoneTemplate...)},
+			},
+			want: []adapter.Template{remote},
+		},
+		{
+			name: "neither URL nor manifest",
+			ops: adapter.Operations{
+				"a": {Templates: []adapter.Template{remote, "istio.yaml"}},
+			},
+			wantCode: adapter.ErrUnbundleableTemplateCode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := adapter.BundleTemplates(tt.ops)
+			if tt.wantCode != "" {
+				if code := errorCode(err); code != tt.wantCode {
+					t.Errorf("BundleTemplates error = %v (code %q), want code %q", err, code, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BundleTemplates: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BundleTemplates = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenBundleErrors(t *testing.T) {
+	dir := t.TempDir()
+	garbage := filepath.Join(dir, "garbage.tar.gz")
+	if err := os.WriteFile(garbage, []byte("not an archive"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	empty := filepath.Join(dir, "empty")
+	if err := os.Mkdir(empty, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, path := range map[string]string{
+		"not found":      filepath.Join(dir, "missing"),
+		"not an archive": garbage,
+		"no index":       empty,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := adapter.OpenBundleFile(path); errorCode(err) != adapter.ErrOpenBundleCode {
+				t.Errorf("OpenBundleFile error = %v, want code %s", err, adapter.ErrOpenBundleCode)
+			}
+		})
+	}
+}
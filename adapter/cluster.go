@@ -0,0 +1,190 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"sync"
+
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// DefaultFanOutConcurrency is the number of clusters FanOut works on at the same time, if FanOutOptions.Concurrency is not set.
+const DefaultFanOutConcurrency = 4
+
+// Cluster identifies a cluster an operation is applied to, by the current context of its kubeconfig and the server
+// of this context. Index is the position of the kubeconfig in the request, e.g. in OperationRequest.K8sConfigs.
+type Cluster struct {
+	Index   int
+	Context string
+	Server  string
+}
+
+// String returns the context and the server of the cluster, e.g. "kind-kind (https://127.0.0.1:6443)", or its index
+// if its kubeconfig could not be read.
+func (c Cluster) String() string {
+	switch {
+	case c.Context == "" && c.Server == "":
+		return fmt.Sprintf("kubeconfig #%d", c.Index)
+	case c.Server == "":
+		return c.Context
+	default:
+		return fmt.Sprintf("%s (%s)", c.Context, c.Server)
+	}
+}
+
+// Tag sets the cluster of the event, and returns it.
+func (c Cluster) Tag(e *meshes.EventsResponse) *meshes.EventsResponse {
+	e.Cluster = c.String()
+	return e
+}
+
+// ClusterOf returns the cluster of the current context of kubeconfig, the index-th kubeconfig of a request.
+func ClusterOf(index int, kubeconfig string) (Cluster, error) {
+	cluster := Cluster{Index: index}
+	cfg, err := clientcmd.Load([]byte(kubeconfig))
+	if err != nil {
+		return cluster, ErrClientConfig(err)
+	}
+	cluster.Context = cfg.CurrentContext
+	if ctx, ok := cfg.Contexts[cfg.CurrentContext]; ok && ctx != nil {
+		if c, ok := cfg.Clusters[ctx.Cluster]; ok && c != nil {
+			cluster.Server = c.Server
+		}
+	}
+	return cluster, nil
+}
+
+// FanOutMode tells how FanOut handles the failure of a cluster.
+type FanOutMode int
+
+const (
+	// BestEffort runs the function on all clusters, whether others failed or not.
+	BestEffort FanOutMode = iota
+	// FailFast cancels the context of the functions still running, and skips the clusters not started yet,
+	// once the function failed on a cluster.
+	FailFast
+)
+
+// FanOutOptions configures FanOut.
+type FanOutOptions struct {
+	// Concurrency is the maximum number of clusters worked on at the same time. Defaults to DefaultFanOutConcurrency.
+	Concurrency int
+	Mode        FanOutMode
+}
+
+// ClusterResult is the result of the function run by FanOut on a cluster.
+type ClusterResult[T any] struct {
+	Cluster Cluster
+	Value   T
+	Err     error
+	// Skipped is set if the function did not run on the cluster, or was cancelled, because another cluster failed
+	// in FailFast mode.
+	Skipped bool
+}
+
+// FanOut runs fn on the cluster of each kubeconfig, with at most opts.Concurrency clusters at the same time, and returns
+// the results in the order of kubeconfigs. The error returned is nil if fn succeeded on all clusters, and lists the
+// clusters fn failed on otherwise. Kubeconfigs that cannot be read fail without running fn.
+// fn must be safe to run concurrently, and should return once ctx is cancelled.
+func FanOut[T any](ctx context.Context, kubeconfigs []string, opts FanOutOptions, fn func(ctx context.Context, cluster Cluster, kubeconfig string) (T, error)) ([]ClusterResult[T], error) {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultFanOutConcurrency
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	aborted := false
+	// fail records the failure of a cluster, and aborts the other clusters in FailFast mode. It returns whether the
+	// error is the cancellation of the cluster, following the failure of another one.
+	fail := func(err error) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		if aborted && stderrors.Is(err, context.Canceled) {
+			return true
+		}
+		if opts.Mode == FailFast {
+			aborted = true
+			cancel()
+		}
+		return false
+	}
+	isAborted := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return aborted
+	}
+
+	results := make([]ClusterResult[T], len(kubeconfigs))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, kubeconfig := range kubeconfigs {
+		result := &results[i]
+		cluster, err := ClusterOf(i, kubeconfig)
+		result.Cluster = cluster
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			result.Err, result.Skipped = ctx.Err(), isAborted()
+			continue
+		}
+		if err != nil {
+			<-sem
+			result.Err = err
+			fail(err)
+			continue
+		}
+
+		wg.Add(1)
+		go func(kubeconfig string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			result.Value, result.Err = fn(ctx, result.Cluster, kubeconfig)
+			if result.Err != nil {
+				result.Skipped = fail(result.Err)
+			}
+		}(kubeconfig)
+	}
+	wg.Wait()
+
+	return results, fanOutErr(results)
+}
+
+// fanOutErr returns the error listing the clusters of results that failed, or nil.
+func fanOutErr[T any](results []ClusterResult[T]) error {
+	failures := make([]string, 0)
+	skipped := 0
+	for _, r := range results {
+		switch {
+		case r.Err == nil:
+		case r.Skipped:
+			skipped++
+		default:
+			failures = append(failures, fmt.Sprintf("%s: %s", r.Cluster, r.Err.Error()))
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	return ErrClusters(failures, skipped, len(results))
+}
@@ -0,0 +1,254 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter_test
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshkit/errors"
+)
+
+const testTimeout = 10 * time.Second
+
+// kubeconfigs returns n kubeconfigs, of the contexts "cluster-0" to "cluster-<n-1>".
+func kubeconfigs(n int) []string {
+	configs := make([]string, n)
+	for i := range configs {
+		configs[i] = fmt.Sprintf(`apiVersion: v1
+kind: Config
+current-context: cluster-%[1]d
+contexts:
+- name: cluster-%[1]d
+  context:
+    cluster: cluster-%[1]d
+clusters:
+- name: cluster-%[1]d
+  cluster:
+    server: https://cluster-%[1]d.example.com:6443
+`, i)
+	}
+	return configs
+}
+
+// checkClustersErr checks that err is the ErrClusters error of failed and skipped clusters, out of total.
+func checkClustersErr(t *testing.T, err error, failed, skipped, total int) {
+	t.Helper()
+	if failed == 0 {
+		if err != nil {
+			t.Errorf("FanOut error = %v, want nil", err)
+		}
+		return
+	}
+	if _, ok := errors.Is(err); !ok || errors.GetCode(err) != adapter.ErrClustersCode {
+		t.Fatalf("FanOut error = %v, want an error with code %s", err, adapter.ErrClustersCode)
+	}
+	want := fmt.Sprintf("Failed on %d of %d cluster(s), %d skipped", failed, total, skipped)
+	if got := err.Error(); !strings.Contains(got, want) {
+		t.Errorf("FanOut error description = %q, want it to contain %q", got, want)
+	}
+}
+
+func TestFanOutConcurrency(t *testing.T) {
+	tests := []struct {
+		name        string
+		concurrency int
+		want        int
+	}{
+		{name: "sequential", concurrency: 1, want: 1},
+		{name: "limited", concurrency: 3, want: 3},
+		{name: "default", concurrency: 0, want: adapter.DefaultFanOutConcurrency},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+			defer cancel()
+
+			var inFlight, maxInFlight, calls atomic.Int32
+			// The functions wait for the limit to be reached once, so that it is, whatever the scheduling.
+			full := make(chan struct{})
+			var fullOnce sync.Once
+			results, err := adapter.FanOut(ctx, kubeconfigs(10), adapter.FanOutOptions{Concurrency: tt.concurrency},
+				func(ctx context.Context, cluster adapter.Cluster, _ string) (int, error) {
+					defer inFlight.Add(-1)
+					calls.Add(1)
+					n := inFlight.Add(1)
+					for m := maxInFlight.Load(); n > m && !maxInFlight.CompareAndSwap(m, n); m = maxInFlight.Load() {
+					}
+					if n == int32(tt.want) {
+						fullOnce.Do(func() { close(full) })
+					}
+					select {
+					case <-full:
+					case <-ctx.Done():
+						return 0, ctx.Err()
+					}
+					return cluster.Index, nil
+				})
+
+			checkClustersErr(t, err, 0, 0, 10)
+			if got := maxInFlight.Load(); got != int32(tt.want) {
+				t.Errorf("at most %d clusters in flight, want %d", got, tt.want)
+			}
+			if got := calls.Load(); got != 10 {
+				t.Errorf("function run on %d clusters, want 10", got)
+			}
+			for i, r := range results {
+				if r.Value != i || r.Cluster.Index != i {
+					t.Errorf("result %d is of cluster %d, with value %d", i, r.Cluster.Index, r.Value)
+				}
+			}
+		})
+	}
+}
+
+func TestFanOutModes(t *testing.T) {
+	errFailed := stderrors.New("cluster unreachable")
+	tests := []struct {
+		name        string
+		mode        adapter.FanOutMode
+		wantCalls   int32
+		wantSkipped []bool
+		wantErrs    []error
+	}{
+		{
+			name:        "best effort",
+			mode:        adapter.BestEffort,
+			wantCalls:   4,
+			wantSkipped: []bool{false, false, false, false},
+			wantErrs:    []error{nil, errFailed, nil, nil},
+		},
+		{
+			name:        "fail fast",
+			mode:        adapter.FailFast,
+			wantCalls:   2,
+			wantSkipped: []bool{false, false, true, true},
+			wantErrs:    []error{nil, errFailed, context.Canceled, context.Canceled},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+			defer cancel()
+
+			var calls atomic.Int32
+			results, _ := adapter.FanOut(ctx, kubeconfigs(4), adapter.FanOutOptions{Concurrency: 1, Mode: tt.mode},
+				func(ctx context.Context, cluster adapter.Cluster, _ string) (struct{}, error) {
+					calls.Add(1)
+					if cluster.Index == 1 {
+						return struct{}{}, errFailed
+					}
+					return struct{}{}, nil
+				})
+
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("function run on %d clusters, want %d", got, tt.wantCalls)
+			}
+			for i, r := range results {
+				if r.Skipped != tt.wantSkipped[i] {
+					t.Errorf("cluster %d skipped = %t, want %t", i, r.Skipped, tt.wantSkipped[i])
+				}
+				if !stderrors.Is(r.Err, tt.wantErrs[i]) {
+					t.Errorf("cluster %d error = %v, want %v", i, r.Err, tt.wantErrs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFanOutFailFastCancelsRunningClusters(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	running := make(chan struct{})
+	results, err := adapter.FanOut(ctx, kubeconfigs(3), adapter.FanOutOptions{Concurrency: 2, Mode: adapter.FailFast},
+		func(ctx context.Context, cluster adapter.Cluster, _ string) (struct{}, error) {
+			if cluster.Index == 0 {
+				// Cluster 0 fails while cluster 1 is running, before cluster 2 starts.
+				<-running
+				return struct{}{}, stderrors.New("cluster unreachable")
+			}
+			if cluster.Index == 1 {
+				close(running)
+			}
+			<-ctx.Done()
+			return struct{}{}, ctx.Err()
+		})
+
+	for i, want := range []bool{false, true, true} {
+		if results[i].Skipped != want {
+			t.Errorf("cluster %d skipped = %t, want %t (error: %v)", i, results[i].Skipped, want, results[i].Err)
+		}
+	}
+	checkClustersErr(t, err, 1, 2, 3)
+	if got := err.Error(); !strings.Contains(got, "cluster-0 (https://cluster-0.example.com:6443): cluster unreachable") {
+		t.Errorf("FanOut error description = %q, want it to list cluster-0", got)
+	}
+}
+
+func TestFanOutCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var calls atomic.Int32
+	results, err := adapter.FanOut(ctx, kubeconfigs(3), adapter.FanOutOptions{Mode: adapter.FailFast},
+		func(ctx context.Context, _ adapter.Cluster, _ string) (struct{}, error) {
+			calls.Add(1)
+			return struct{}{}, nil
+		})
+
+	if got := calls.Load(); got != 0 {
+		t.Errorf("function run on %d clusters of a cancelled context", got)
+	}
+	for i, r := range results {
+		if !stderrors.Is(r.Err, context.Canceled) || r.Skipped {
+			t.Errorf("cluster %d: error %v, skipped %t, want context.Canceled, not skipped", i, r.Err, r.Skipped)
+		}
+	}
+	// The clusters were not skipped because of another one, they all failed.
+	checkClustersErr(t, err, 3, 0, 3)
+}
+
+func TestFanOutInvalidKubeconfig(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	configs := kubeconfigs(3)
+	configs[1] = "not: [a kubeconfig"
+	var calls atomic.Int32
+	results, err := adapter.FanOut(ctx, configs, adapter.FanOutOptions{},
+		func(ctx context.Context, _ adapter.Cluster, _ string) (struct{}, error) {
+			calls.Add(1)
+			return struct{}{}, nil
+		})
+
+	if got := calls.Load(); got != 2 {
+		t.Errorf("function run on %d clusters, want 2", got)
+	}
+	if code := errors.GetCode(results[1].Err); code != adapter.ErrClientConfigCode {
+		t.Errorf("error of the invalid kubeconfig has code %q, want %q", code, adapter.ErrClientConfigCode)
+	}
+	if got := results[1].Cluster.String(); got != "kubeconfig #1" {
+		t.Errorf("cluster of the invalid kubeconfig = %q, want %q", got, "kubeconfig #1")
+	}
+	checkClustersErr(t, err, 1, 0, 3)
+}
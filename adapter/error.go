@@ -15,6 +15,9 @@
 package adapter
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/layer5io/meshkit/errors"
 )
 
@@ -43,6 +46,16 @@ const (
 	ErrGenerateComponentsCode   = "1011"
 	ErrAuthInfosInvalidMsgCode  = "1012"
 	ErrCreatingComponentsCode   = "1013"
+	ErrReadTemplateCode         = "1017"
+	ErrClustersCode             = "1018"
+	ErrRenderTemplateCode       = "1019"
+	ErrTemplateNotCachedCode    = "1020"
+	ErrTemplateChecksumCode     = "1021"
+	ErrOpenBundleCode           = "1022"
+	ErrBundleIncompleteCode     = "1023"
+	ErrWriteBundleCode          = "1024"
+	ErrUnbundleableTemplateCode = "1025"
+	ErrOperationRequestCode     = "1026"
 )
 
 var (
@@ -84,6 +97,63 @@ func ErrClientSet(err error) error {
 func ErrStreamEvent(err error) error {
 	return errors.New(ErrStreamEventCode, errors.Alert, []string{"Error streaming event"}, []string{err.Error()}, []string{}, []string{})
 }
+
+// ErrApplyOperation is the error for an operation failing to apply its manifests
+func ErrApplyOperation(err error) error {
+	return errors.New(ErrApplyOperationCode, errors.Alert, []string{"Error applying operation"}, []string{err.Error()}, []string{"The manifests of the operation are invalid", "The cluster is not reachable, or the kubeconfig is not allowed to manage the resources of the manifests"}, []string{"Check the manifests of the operation, or the custom body of custom operations", "Check that the cluster is reachable with the kubeconfig, and that it is allowed to manage the resources"})
+}
+
+// ErrReadTemplate is the error for a template of an operation that cannot be read
+func ErrReadTemplate(source string, err error) error {
+	return errors.New(ErrReadTemplateCode, errors.Alert, []string{"Error reading operation template"}, []string{fmt.Sprintf("Template %s: %s", source, err.Error())}, []string{"The URL of the template is wrong, or not reachable from the adapter"}, []string{"Check the templates of the operation in the adapter configuration"})
+}
+
+// ErrClusters is the error for a function run by FanOut that failed on some clusters
+func ErrClusters(failures []string, skipped, total int) error {
+	long := fmt.Sprintf("Failed on %d of %d cluster(s), %d skipped: %s", len(failures), total, skipped, strings.Join(failures, "; "))
+	return errors.New(ErrClustersCode, errors.Alert, []string{"Error on one or more clusters"}, []string{long}, []string{"The clusters are not reachable, or the kubeconfigs are not allowed to manage the resources"}, []string{"Check the errors of each cluster"})
+}
+
+// ErrRenderTemplate is the error for a template of an operation that cannot be rendered
+func ErrRenderTemplate(name string, err error) error {
+	return errors.New(ErrRenderTemplateCode, errors.Alert, []string{"Error rendering operation template"}, []string{fmt.Sprintf("Template %s: %s", name, err.Error())}, []string{"The template is not a valid Go template", "The template references a value that is not set by the request nor by the additional properties of the operation"}, []string{"Check the syntax of the template", "Set the missing value in the values of the request, or in the additional properties of the operation"})
+}
+
+// ErrTemplateNotCached is the error for a remote template that is not in the cache, in cache-only mode
+func ErrTemplateNotCached(source string) error {
+	return errors.New(ErrTemplateNotCachedCode, errors.Alert, []string{"Operation template not cached"}, []string{fmt.Sprintf("Template %s is not in the cache, and remote templates are only read from the cache", source)}, []string{"The template has not been fetched since the cache was created, or the cache directory is not the one the template was fetched into"}, []string{"Fetch the template into the cache from a host with network access, or disable the cache-only mode"})
+}
+
+// ErrTemplateChecksum is the error for a pinned template whose contents do not match its checksum
+func ErrTemplateChecksum(source, reason string) error {
+	return errors.New(ErrTemplateChecksumCode, errors.Alert, []string{"Operation template checksum mismatch"}, []string{fmt.Sprintf("Template %s: %s", source, reason)}, []string{"The template has changed since it was pinned", "The checksum of the template is malformed"}, []string{"Check that the template is the expected one, and update its checksum"})
+}
+
+// ErrOpenBundle is the error for a template bundle that cannot be read
+func ErrOpenBundle(err error) error {
+	return errors.New(ErrOpenBundleCode, errors.Alert, []string{"Error opening template bundle"}, []string{err.Error()}, []string{"The bundle does not exist, or is not a directory or a gzipped tar archive with a bundle.json index"}, []string{"Build the bundle again, e.g. with adapterctl bundle build"})
+}
+
+// ErrBundleIncomplete is the error for a template bundle missing templates of the operations
+func ErrBundleIncomplete(missing []string) error {
+	return errors.New(ErrBundleIncompleteCode, errors.Alert, []string{"Template bundle incomplete"}, []string{fmt.Sprintf("The bundle is missing %d template(s), or holds them corrupted: %s", len(missing), strings.Join(missing, ", "))}, []string{"The bundle was built for another version of the adapter", "The operations have changed since the bundle was built"}, []string{"Build the bundle again from the operations of the adapter"})
+}
+
+// ErrWriteBundle is the error for a template bundle that cannot be written
+func ErrWriteBundle(err error) error {
+	return errors.New(ErrWriteBundleCode, errors.Alert, []string{"Error writing template bundle"}, []string{err.Error()}, []string{}, []string{"Check that the destination of the bundle is writable"})
+}
+
+// ErrUnbundleableTemplate is the error for templates that are neither manifests nor URLs, and cannot be bundled
+func ErrUnbundleableTemplate(templates []string) error {
+	return errors.New(ErrUnbundleableTemplateCode, errors.Alert, []string{"Operation templates cannot be bundled"}, []string{fmt.Sprintf("%d template(s) are neither manifests nor file:// or http(s):// URLs: %s", len(templates), strings.Join(templates, ", "))}, []string{"The adapter redacts the URLs of its templates, and only returns their file names"}, []string{"Use the operations of the adapter with the URLs of their templates, e.g. from its configuration with the --operations flag of adapterctl bundle"})
+}
+
+// ErrOperationRequest is the error for an operation request missing what the operation needs to be applied
+func ErrOperationRequest(reason string) error {
+	return errors.New(ErrOperationRequestCode, errors.Alert, []string{"Invalid operation request"}, []string{reason}, []string{}, []string{"Set the kubeconfigs of the clusters to apply the operation to, and the manifest of custom operations"})
+}
+
 func ErrListOperations(err error) error {
 	return errors.New(ErrListOperationsCode, errors.Alert, []string{"Error listing operations"}, []string{err.Error()}, []string{}, []string{})
 }
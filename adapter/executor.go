@@ -0,0 +1,159 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	"github.com/layer5io/meshery-adapter-library/status"
+	"github.com/layer5io/meshkit/errors"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+)
+
+// ApplyOperation applies the operations of category SAMPLE_APPLICATION and CUSTOM, e.g. the sample applications and
+// the custom operation of common.Operations, to each cluster of op.K8sConfigs. The manifests applied are the templates
+// of the operation, read with the TemplateResolver of the adapter and rendered if the operation has RenderTemplates set,
+// or op.CustomBody for custom operations,
+// in op.Namespace. They are deleted if op.IsDeleteOperation is set.
+// A Deploying, or on deletion Removing, event is published with the operation ID, followed by a Deployed or Removed event,
+// or an error event, for each cluster, tagged with the cluster. The operation fails if it failed on any cluster.
+//
+// Adapters implement the operations specific to their mesh in their own ApplyOperation, and call this one for the others.
+// Operations of other categories fail with ErrOpInvalid, and requests without kubeconfig, or custom operations without
+// manifest, with ErrOperationRequest.
+func (h *Adapter) ApplyOperation(ctx context.Context, op OperationRequest) error {
+	operations, err := h.ListOperations()
+	if err != nil {
+		return err
+	}
+	desc, ok := operations[op.OperationName]
+	if !ok || desc == nil {
+		return ErrOpInvalid
+	}
+	if len(op.K8sConfigs) == 0 {
+		return ErrOperationRequest(fmt.Sprintf("Operation %s has no kubeconfig to apply it to", op.OperationName))
+	}
+
+	var manifests []string
+	switch desc.Type {
+	case int32(meshes.OpCategory_SAMPLE_APPLICATION):
+		for _, t := range desc.Templates {
+			if t == NoneTemplate[0] {
+				continue
+			}
+			manifest, err := h.templateResolver().Resolve(ctx, t)
+			if err == nil && desc.RenderTemplates {
+				manifest, err = RenderTemplate(t.Source(), manifest, NewTemplateData(op, desc))
+			}
+			if err != nil {
+				h.streamOperationErr(op, nil, err)
+				return err
+			}
+			manifests = append(manifests, manifest)
+		}
+	case int32(meshes.OpCategory_CUSTOM):
+		if strings.TrimSpace(op.CustomBody) == "" {
+			return ErrOperationRequest(fmt.Sprintf("Custom operation %s has no manifest", op.OperationName))
+		}
+		manifests = append(manifests, op.CustomBody)
+	default:
+		return ErrOpInvalid
+	}
+
+	name := desc.Description
+	if name == "" {
+		name = op.OperationName
+	}
+	progress, done := status.Deploying, status.Deployed
+	if op.IsDeleteOperation {
+		progress, done = status.Removing, status.Removed
+	}
+	h.StreamInfo(&meshes.EventsResponse{
+		OperationId: op.OperationID,
+		Summary:     fmt.Sprintf("%s %s", name, progress),
+		Details:     fmt.Sprintf("%s %s on %d cluster(s)", name, progress, len(op.K8sConfigs)),
+	})
+
+	opts := mesherykube.ApplyOptions{
+		Namespace: op.Namespace,
+		Update:    true,
+		Delete:    op.IsDeleteOperation,
+	}
+	results, err := FanOut(ctx, op.K8sConfigs, FanOutOptions{Mode: BestEffort}, func(ctx context.Context, cluster Cluster, kubeconfig string) (struct{}, error) {
+		if err := h.ApplyManifests(ctx, kubeconfig, manifests, opts); err != nil {
+			return struct{}{}, err
+		}
+		h.StreamInfo(cluster.Tag(&meshes.EventsResponse{
+			OperationId: op.OperationID,
+			Summary:     fmt.Sprintf("%s %s successfully", name, done),
+			Details:     fmt.Sprintf("%s %s on %s", name, done, cluster),
+		}))
+		return struct{}{}, nil
+	})
+	for _, result := range results {
+		if result.Err != nil {
+			h.streamOperationErr(op, &result.Cluster, result.Err)
+		}
+	}
+	return err
+}
+
+// ApplyManifests applies the manifests, in order, to the cluster of kubeconfig with the given options.
+// It stops at the first failure, or once ctx is cancelled. See FanOut to apply them to several clusters.
+func (h *Adapter) ApplyManifests(ctx context.Context, kubeconfig string, manifests []string, opts mesherykube.ApplyOptions) error {
+	kclient, err := mesherykube.New([]byte(kubeconfig))
+	if err != nil {
+		return ErrClientConfig(err)
+	}
+	for _, manifest := range manifests {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := kclient.ApplyManifest([]byte(manifest), opts); err != nil {
+			return ErrApplyOperation(err)
+		}
+	}
+	return nil
+}
+
+// templateResolver returns the TemplateResolver of the adapter, or DefaultTemplateResolver.
+func (h *Adapter) templateResolver() *TemplateResolver {
+	if h.TemplateResolver != nil {
+		return h.TemplateResolver
+	}
+	return DefaultTemplateResolver
+}
+
+// streamOperationErr publishes an error event for the operation, tagged with the cluster it failed on if any,
+// with the details of err if it is a MeshKit error.
+func (h *Adapter) streamOperationErr(op OperationRequest, cluster *Cluster, err error) {
+	e := &meshes.EventsResponse{
+		OperationId: op.OperationID,
+		Summary:     fmt.Sprintf("Error applying operation %s", op.OperationName),
+		Details:     err.Error(),
+	}
+	if _, ok := errors.Is(err); ok {
+		e.ErrorCode = errors.GetCode(err)
+		e.ProbableCause = errors.GetCause(err)
+		e.SuggestedRemediation = errors.GetRemedy(err)
+	}
+	if cluster != nil {
+		cluster.Tag(e)
+	}
+	h.StreamErr(e, err)
+}
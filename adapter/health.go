@@ -0,0 +1,46 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import (
+	"context"
+	"sync"
+)
+
+// HealthChecker can be implemented by a Handler to report its own health, in addition to the readiness checks
+// configured in the gRPC service. A non-nil error means the handler is degraded, and the adapter is reported as NOT_SERVING.
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) error
+}
+
+// adapterHealth holds the degraded state reported by an adapter.
+type adapterHealth struct {
+	mx       sync.Mutex
+	degraded error
+}
+
+// SetDegraded marks the adapter as degraded for the reason given by err, or as healthy again if err is nil.
+func (h *Adapter) SetDegraded(err error) {
+	h.health.mx.Lock()
+	defer h.health.mx.Unlock()
+	h.health.degraded = err
+}
+
+// CheckHealth implements HealthChecker, reporting the reason passed to SetDegraded, if any.
+func (h *Adapter) CheckHealth(ctx context.Context) error {
+	h.health.mx.Lock()
+	defer h.health.mx.Unlock()
+	return h.health.degraded
+}
@@ -86,6 +86,23 @@ func (s *adapterLogger) ListOperations() (Operations, error) {
 	return ops, err
 }
 
+// GetVersion forwards to the Handler's GetVersion method, if it has one, e.g. Adapter.GetVersion.
+func (s *adapterLogger) GetVersion() string {
+	if h, ok := s.next.(interface{ GetVersion() string }); ok {
+		return h.GetVersion()
+	}
+	return ""
+}
+
+// CheckHealth forwards to the Handler's CheckHealth method, if it implements HealthChecker.
+func (s *adapterLogger) CheckHealth(ctx context.Context) error {
+	hc, ok := s.next.(HealthChecker)
+	if !ok {
+		return nil
+	}
+	return hc.CheckHealth(ctx)
+}
+
 func (s *adapterLogger) StreamErr(e *meshes.EventsResponse, err error) {
 	s.log.Error(err)
 }
@@ -16,9 +16,7 @@ package adapter
 
 import (
 	"context"
-	"net/url"
-
-	"github.com/layer5io/meshkit/utils"
+	"os"
 )
 
 var (
@@ -36,17 +34,21 @@ type Template string
 
 type Service string
 
+// String returns the contents of the template, see Resolve, or an empty string if they cannot be read.
+//
+// Deprecated: use Resolve, which returns the error the template cannot be read with.
 func (t Template) String() string {
-	_, err := url.ParseRequestURI(string(t))
-	if err != nil {
-		return string(t)
+	r := DefaultTemplateResolver
+	// The cache is skipped if its directory cannot be created, e.g. in a read-only home directory.
+	if r.CacheDir != "" && os.MkdirAll(r.CacheDir, 0o755) != nil {
+		uncached := *r
+		uncached.CacheDir = ""
+		r = &uncached
 	}
-
-	st, err := utils.ReadFileSource(string(t))
+	st, err := r.Resolve(context.Background(), t)
 	if err != nil {
 		return ""
 	}
-
 	return st
 }
 
@@ -58,6 +60,9 @@ type Operation struct {
 	Templates            []Template        `json:"templates,omitempty"`
 	Services             []Service         `json:"services,omitempty"`
 	AdditionalProperties map[string]string `json:"additional_properties,omitempty"`
+	// RenderTemplates is set if the templates are Go templates, rendered with the values of the request
+	// before being applied, see RenderTemplate.
+	RenderTemplates bool `json:"render_templates,omitempty"`
 }
 
 // Operations contains all operations supported by an adapter.
@@ -72,15 +77,19 @@ type OperationRequest struct {
 	IsDeleteOperation bool   // If true, the operation specified by OperationName is reverted, i.e. all resources created are deleted.
 	OperationID       string // ID of the operation, if any. This identifies a specific operation invocation.
 	K8sConfigs        []string
-	Version           string // Service mesh version to be installed, defaults to latest version.
+	Version           string            // Service mesh version to be installed, defaults to latest version.
+	Mesh              string            // Mesh the operation is for, in adapters hosting several meshes, see grpc.Service.Meshes.
+	Values            map[string]string // Values overriding the AdditionalProperties of the operation in its templates, see TemplateData.
 }
 
 type OAMRequest struct {
-	Username   string
-	DeleteOp   bool
-	OamComps   []string
-	OamConfig  string
-	K8sConfigs []string
+	Username    string
+	DeleteOp    bool
+	OamComps    []string
+	OamConfig   string
+	K8sConfigs  []string
+	OperationID string
+	Mesh        string // Mesh the components are for, in adapters hosting several meshes.
 }
 
 // List all operations an adapter supports.
@@ -92,8 +101,3 @@ func (h *Adapter) ListOperations() (Operations, error) {
 	}
 	return operations, nil
 }
-
-// Applies an adapter operation. This is adapter specific and needs to be implemented by each adapter.
-func (h *Adapter) ApplyOperation(ctx context.Context, op OperationRequest, hchan *chan interface{}) error {
-	return nil
-}
@@ -22,7 +22,46 @@ type meshmodelDefinitionPathSet struct {
 	meshmodelDefinitionPath string
 }
 
+const (
+	MeshModelRegistrationNotStarted = "not started"
+	MeshModelRegistrationInProgress = "in progress"
+	MeshModelRegistrationCompleted  = "completed"
+	MeshModelRegistrationFailed     = "failed"
+)
+
+var (
+	registrationLock  sync.Mutex
+	registrationState = MeshModelRegistrationNotStarted
+	registrationErr   error
+)
+
+// MeshModelRegistrationState returns the state of the meshmodel components registration (see RegisterMeshModelComponents),
+// and the error it failed with, if any.
+func MeshModelRegistrationState() (string, error) {
+	registrationLock.Lock()
+	defer registrationLock.Unlock()
+	return registrationState, registrationErr
+}
+
+func setMeshModelRegistrationState(state string, err error) {
+	registrationLock.Lock()
+	defer registrationLock.Unlock()
+	registrationState = state
+	registrationErr = err
+}
+
 func RegisterMeshModelComponents(uuid, runtime, host, port string) error {
+	setMeshModelRegistrationState(MeshModelRegistrationInProgress, nil)
+	err := registerMeshModelComponents(uuid, runtime, host, port)
+	if err != nil {
+		setMeshModelRegistrationState(MeshModelRegistrationFailed, err)
+		return err
+	}
+	setMeshModelRegistrationState(MeshModelRegistrationCompleted, nil)
+	return nil
+}
+
+func registerMeshModelComponents(uuid, runtime, host, port string) error {
 	meshmodelRDP := []MeshModelRegistrantDefinitionPath{}
 	pathSets, err := loadMeshmodelComponents(MeshmodelComponents)
 	if err != nil {
@@ -0,0 +1,84 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import (
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+)
+
+// TemplateData is the data templates are rendered with, see RenderTemplate, e.g. {{ .Namespace }} or
+// {{ .Values.service_name }}.
+type TemplateData struct {
+	Namespace   string
+	Version     string // The version requested, or the latest version of the operation.
+	Username    string
+	OperationID string
+	Mesh        string
+	// Values are the AdditionalProperties of the operation, overridden by the values of the request, see OperationRequest.Values.
+	Values map[string]string
+}
+
+// NewTemplateData returns the data the templates of the operation op are rendered with, for the request req.
+func NewTemplateData(req OperationRequest, op *Operation) TemplateData {
+	data := TemplateData{
+		Namespace:   req.Namespace,
+		Version:     req.Version,
+		Username:    req.Username,
+		OperationID: req.OperationID,
+		Mesh:        req.Mesh,
+		Values:      make(map[string]string),
+	}
+	if op == nil {
+		op = &Operation{}
+	}
+	if data.Version == "" {
+		versions := make([]string, 0, len(op.Versions))
+		for _, v := range op.Versions {
+			versions = append(versions, v.String())
+		}
+		if sorted := SortVersions(versions); len(sorted) > 0 {
+			data.Version = sorted[len(sorted)-1]
+		}
+	}
+	for k, v := range op.AdditionalProperties {
+		data.Values[k] = v
+	}
+	for k, v := range req.Values {
+		data.Values[k] = v
+	}
+	return data
+}
+
+// RenderTemplate renders the contents of the template name with text/template, the sprig functions and data.
+// It fails if the template references a value that is not set, e.g. a key missing from data.Values.
+// The sprig functions reading the environment of the adapter are not available.
+func RenderTemplate(name, contents string, data TemplateData) (string, error) {
+	funcs := sprig.TxtFuncMap()
+	delete(funcs, "env")
+	delete(funcs, "expandenv")
+
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(contents)
+	if err != nil {
+		return "", ErrRenderTemplate(name, err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", ErrRenderTemplate(name, err)
+	}
+	return b.String(), nil
+}
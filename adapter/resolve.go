@@ -0,0 +1,262 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/layer5io/meshkit/utils"
+)
+
+const (
+	// DefaultTemplateTimeout is the time TemplateResolver waits for a remote template, if its Timeout is not set.
+	DefaultTemplateTimeout = 30 * time.Second
+
+	// ChecksumSeparator separates the source of a template from its checksum, if it is pinned, e.g.
+	// "https://example.com/app.yaml@sha256:<hex digest of the contents>".
+	ChecksumSeparator = "@sha256:"
+)
+
+// DefaultTemplateResolver resolves the templates of adapters without a TemplateResolver, and Template.Resolve.
+var DefaultTemplateResolver = &TemplateResolver{
+	CacheDir: filepath.Join(utils.GetHome(), ".meshery", "cache", "templates"),
+}
+
+// TemplateResolver reads the contents of templates, see Template.Resolve.
+// Remote templates are kept in a content-addressed cache, pinned templates are read from it without fetching them again.
+type TemplateResolver struct {
+	// CacheDir is the directory of the cache of remote templates. The cache is disabled if empty.
+	CacheDir string
+	// CacheOnly is set to only read remote templates from the cache, e.g. in air-gapped clusters.
+	CacheOnly bool
+	// Timeout is the time to wait for a remote template. Defaults to DefaultTemplateTimeout.
+	Timeout time.Duration
+	// HTTPClient fetches remote templates. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+	// Bundle serves the templates it holds, before the files, the cache and the network are looked up.
+	Bundle *Bundle
+}
+
+// Resolve returns the contents of the template using DefaultTemplateResolver, see TemplateResolver.Resolve.
+func (t Template) Resolve(ctx context.Context) (string, error) {
+	return DefaultTemplateResolver.Resolve(ctx, t)
+}
+
+// Source returns the template without its checksum, if it is pinned.
+func (t Template) Source() string {
+	source, _ := t.split()
+	return source
+}
+
+// Checksum returns the hex SHA-256 digest the contents of the template are pinned to, if any.
+func (t Template) Checksum() string {
+	_, checksum := t.split()
+	return checksum
+}
+
+// IsInline reports whether the template is a manifest itself, rather than the file:// or http(s):// URL of one.
+func (t Template) IsInline() bool {
+	return !isLocal(string(t)) && !isRemote(string(t))
+}
+
+func (t Template) split() (string, string) {
+	if t.IsInline() {
+		return string(t), ""
+	}
+	if i := strings.LastIndex(string(t), ChecksumSeparator); i >= 0 {
+		return string(t)[:i], string(t)[i+len(ChecksumSeparator):]
+	}
+	return string(t), ""
+}
+
+func isLocal(source string) bool {
+	return strings.HasPrefix(source, "file://")
+}
+
+func isRemote(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+// Resolve returns the contents of the template t: the template itself if it is inline, or the contents of the file
+// or the remote resource it refers to, taken from the Bundle if it holds them. The contents of a pinned template must
+// match its checksum.
+// Remote templates are stored in the cache once fetched. Pinned ones are read from the cache if they are in it,
+// all remote templates are read from the cache in CacheOnly mode.
+func (r *TemplateResolver) Resolve(ctx context.Context, t Template) (string, error) {
+	if t.IsInline() {
+		return string(t), nil
+	}
+	source, checksum := t.split()
+	if checksum != "" && !validChecksum(checksum) {
+		return "", ErrTemplateChecksum(source, fmt.Sprintf("invalid checksum %q, expected a hex SHA-256 digest", checksum))
+	}
+
+	if r.Bundle != nil {
+		if contents, ok := r.Bundle.lookup(source, checksum); ok {
+			return contents, nil
+		}
+	}
+
+	if isLocal(source) {
+		b, err := os.ReadFile(strings.TrimPrefix(source, "file://"))
+		if err != nil {
+			return "", ErrReadTemplate(source, err)
+		}
+		return verifyChecksum(source, checksum, string(b))
+	}
+
+	if checksum == "" && r.CacheOnly {
+		checksum = r.indexed(source)
+	}
+	if checksum != "" {
+		if contents, ok := r.cached(checksum); ok {
+			return contents, nil
+		}
+	}
+	if r.CacheOnly {
+		return "", ErrTemplateNotCached(source)
+	}
+
+	contents, err := r.fetch(ctx, source)
+	if err != nil {
+		return "", ErrReadTemplate(source, err)
+	}
+	if _, err := verifyChecksum(source, checksum, contents); err != nil {
+		return "", err
+	}
+	// The template is usable whether it could be cached or not.
+	_ = r.store(source, contents)
+	return contents, nil
+}
+
+func (r *TemplateResolver) fetch(ctx context.Context, source string) (string, error) {
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTemplateTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return "", err
+	}
+	client := r.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("GET %s: %s", source, resp.Status)
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// The cache stores the contents of templates by digest in CacheDir/sha256, and the digest of the contents last fetched
+// from each source, by digest of the source, in CacheDir/sources.
+
+func (r *TemplateResolver) cached(checksum string) (string, bool) {
+	if r.CacheDir == "" {
+		return "", false
+	}
+	b, err := os.ReadFile(filepath.Join(r.CacheDir, "sha256", checksum))
+	if err != nil || digest(string(b)) != checksum {
+		return "", false
+	}
+	return string(b), true
+}
+
+func (r *TemplateResolver) indexed(source string) string {
+	if r.CacheDir == "" {
+		return ""
+	}
+	b, err := os.ReadFile(filepath.Join(r.CacheDir, "sources", digest(source)))
+	if err != nil || !validChecksum(string(b)) {
+		return ""
+	}
+	return string(b)
+}
+
+func (r *TemplateResolver) store(source, contents string) error {
+	if r.CacheDir == "" {
+		return nil
+	}
+	checksum := digest(contents)
+	if err := writeFileAtomic(filepath.Join(r.CacheDir, "sha256", checksum), []byte(contents)); err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(r.CacheDir, "sources", digest(source)), []byte(checksum))
+}
+
+// writeFileAtomic writes the file through a temporary file, so that concurrent readers never see it partially written.
+func writeFileAtomic(name string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(name), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), name)
+}
+
+func digest(contents string) string {
+	sum := sha256.Sum256([]byte(contents))
+	return hex.EncodeToString(sum[:])
+}
+
+func validChecksum(checksum string) bool {
+	if len(checksum) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(checksum)
+	return err == nil && strings.ToLower(checksum) == checksum
+}
+
+func verifyChecksum(source, checksum, contents string) (string, error) {
+	if checksum == "" {
+		return contents, nil
+	}
+	if actual := digest(contents); actual != checksum {
+		return "", ErrTemplateChecksum(source, fmt.Sprintf("expected sha256 %s, got %s", checksum, actual))
+	}
+	return contents, nil
+}
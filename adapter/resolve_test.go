@@ -0,0 +1,227 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshkit/errors"
+)
+
+const manifest = "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: test\n"
+
+// errorCode returns the MeshKit error code of err, or an empty string.
+func errorCode(err error) string {
+	if _, ok := errors.Is(err); !ok {
+		return ""
+	}
+	return errors.GetCode(err)
+}
+
+func sha256Hex(contents string) string {
+	sum := sha256.Sum256([]byte(contents))
+	return hex.EncodeToString(sum[:])
+}
+
+// templateServer serves manifest on /manifest.yaml, and fails the other paths with 404. It counts the requests.
+func templateServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/manifest.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(manifest))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestResolveInline(t *testing.T) {
+	r := &adapter.TemplateResolver{CacheDir: t.TempDir()}
+	got, err := r.Resolve(context.Background(), adapter.Template(manifest))
+	if err != nil || got != manifest {
+		t.Errorf("Resolve of an inline template = %q, %v, want the template itself", got, err)
+	}
+}
+
+func TestResolveCache(t *testing.T) {
+	srv, requests := templateServer(t)
+	source := srv.URL + "/manifest.yaml"
+	pinned := adapter.Template(source + adapter.ChecksumSeparator + sha256Hex(manifest))
+	r := &adapter.TemplateResolver{CacheDir: t.TempDir()}
+	ctx := context.Background()
+
+	// A pinned template is fetched on a cache miss, and read from the cache afterwards.
+	for i := 0; i < 2; i++ {
+		got, err := r.Resolve(ctx, pinned)
+		if err != nil || got != manifest {
+			t.Fatalf("Resolve #%d of the pinned template = %q, %v", i, got, err)
+		}
+		if n := requests.Load(); n != 1 {
+			t.Errorf("after Resolve #%d of the pinned template, %d requests, want 1", i, n)
+		}
+	}
+
+	// An unpinned template is fetched every time, as it may have changed.
+	if _, err := r.Resolve(ctx, adapter.Template(source)); err != nil {
+		t.Fatalf("Resolve of the unpinned template: %v", err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("after Resolve of the unpinned template, %d requests, want 2", n)
+	}
+
+	// A corrupted cache entry is a cache miss.
+	if err := os.WriteFile(filepath.Join(r.CacheDir, "sha256", sha256Hex(manifest)), []byte("corrupted"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := r.Resolve(ctx, pinned); err != nil || got != manifest {
+		t.Errorf("Resolve of the pinned template with a corrupted cache = %q, %v", got, err)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Errorf("after Resolve with a corrupted cache, %d requests, want 3", n)
+	}
+}
+
+func TestResolveChecksum(t *testing.T) {
+	srv, _ := templateServer(t)
+	dir := t.TempDir()
+	file := filepath.Join(dir, "manifest.yaml")
+	if err := os.WriteFile(file, []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	wrong := sha256Hex("another manifest")
+
+	tests := []struct {
+		name     string
+		template string
+		wantCode string
+	}{
+		{name: "remote pinned", template: srv.URL + "/manifest.yaml@sha256:" + sha256Hex(manifest)},
+		{name: "remote mismatch", template: srv.URL + "/manifest.yaml@sha256:" + wrong, wantCode: adapter.ErrTemplateChecksumCode},
+		{name: "file pinned", template: "file://" + file + "@sha256:" + sha256Hex(manifest)},
+		{name: "file mismatch", template: "file://" + file + "@sha256:" + wrong, wantCode: adapter.ErrTemplateChecksumCode},
+		{name: "malformed checksum", template: srv.URL + "/manifest.yaml@sha256:abc", wantCode: adapter.ErrTemplateChecksumCode},
+		{name: "uppercase checksum", template: srv.URL + "/manifest.yaml@sha256:" + strings.ToUpper(sha256Hex(manifest)), wantCode: adapter.ErrTemplateChecksumCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &adapter.TemplateResolver{CacheDir: t.TempDir()}
+			got, err := r.Resolve(context.Background(), adapter.Template(tt.template))
+			if tt.wantCode != "" {
+				if code := errorCode(err); code != tt.wantCode {
+					t.Errorf("Resolve error = %v (code %q), want code %q", err, code, tt.wantCode)
+				}
+				if _, statErr := os.Stat(filepath.Join(r.CacheDir, "sha256")); !os.IsNotExist(statErr) {
+					t.Error("template failing verification stored in the cache")
+				}
+				return
+			}
+			if err != nil || got != manifest {
+				t.Errorf("Resolve = %q, %v, want the manifest", got, err)
+			}
+		})
+	}
+}
+
+func TestResolveCacheOnly(t *testing.T) {
+	srv, requests := templateServer(t)
+	source := adapter.Template(srv.URL + "/manifest.yaml")
+	dir := t.TempDir()
+	ctx := context.Background()
+	offline := &adapter.TemplateResolver{CacheDir: dir, CacheOnly: true}
+
+	// Cold cache.
+	for _, tmpl := range []adapter.Template{source, source + adapter.ChecksumSeparator + adapter.Template(sha256Hex(manifest))} {
+		if _, err := offline.Resolve(ctx, tmpl); errorCode(err) != adapter.ErrTemplateNotCachedCode {
+			t.Errorf("Resolve of %s with a cold cache: error %v, want code %s", tmpl, err, adapter.ErrTemplateNotCachedCode)
+		}
+	}
+	if n := requests.Load(); n != 0 {
+		t.Fatalf("%d requests in cache-only mode", n)
+	}
+
+	// Warm cache, the unpinned template is found by its source.
+	online := &adapter.TemplateResolver{CacheDir: dir}
+	if _, err := online.Resolve(ctx, source); err != nil {
+		t.Fatalf("Resolve warming the cache: %v", err)
+	}
+	if got, err := offline.Resolve(ctx, source); err != nil || got != manifest {
+		t.Errorf("Resolve with a warm cache = %q, %v", got, err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("%d requests, want the one warming the cache", n)
+	}
+}
+
+func TestResolveHTTPErrors(t *testing.T) {
+	srv, _ := templateServer(t)
+	blocked := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-blocked:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(slow.Close)
+	t.Cleanup(func() { close(blocked) })
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	tests := []struct {
+		name     string
+		template string
+	}{
+		{name: "not found", template: srv.URL + "/missing.yaml"},
+		{name: "connection refused", template: closed.URL + "/manifest.yaml"},
+		{name: "timeout", template: slow.URL + "/manifest.yaml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &adapter.TemplateResolver{CacheDir: t.TempDir(), Timeout: 100 * time.Millisecond}
+			if _, err := r.Resolve(context.Background(), adapter.Template(tt.template)); errorCode(err) != adapter.ErrReadTemplateCode {
+				t.Errorf("Resolve error = %v, want code %s", err, adapter.ErrReadTemplateCode)
+			}
+		})
+	}
+}
+
+func TestTemplateStringWithoutCache(t *testing.T) {
+	srv, _ := templateServer(t)
+	// The cache directory cannot be created under a regular file.
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	saved := adapter.DefaultTemplateResolver
+	adapter.DefaultTemplateResolver = &adapter.TemplateResolver{CacheDir: filepath.Join(file, "cache")}
+	t.Cleanup(func() { adapter.DefaultTemplateResolver = saved })
+
+	if got := adapter.Template(srv.URL + "/manifest.yaml").String(); got != manifest {
+		t.Errorf("String = %q, want the manifest", got)
+	}
+}
@@ -19,13 +19,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/layer5io/learn-layer5/smi-conformance/conformance"
 	"github.com/layer5io/meshery-adapter-library/meshes"
-	"github.com/layer5io/meshery-adapter-library/status"
-	"github.com/layer5io/meshkit/utils"
 	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
 	smp "github.com/layer5io/service-mesh-performance/spec"
 	"golang.org/x/text/cases"
@@ -40,6 +37,7 @@ type SMITest struct {
 	smiAddress  string
 	annotations map[string]string
 	labels      map[string]string
+	templates   *TemplateResolver
 }
 
 type Response struct {
@@ -51,6 +49,15 @@ type Response struct {
 	PassingPercentage string    `json:"passing_percentage,omitempty"`
 	Status            string    `json:"status,omitempty"`
 	MoreDetails       []*Detail `json:"more_details,omitempty"`
+	// Clusters are the results of each cluster the test ran on. The cases passed and the details of the test
+	// are also set in the response itself if it ran on a single cluster.
+	Clusters []*ClusterResponse `json:"clusters,omitempty"`
+}
+
+// ClusterResponse is the response of the SMI test on a cluster, see Cluster.
+type ClusterResponse struct {
+	Cluster string `json:"cluster,omitempty"`
+	Response
 }
 
 type Detail struct {
@@ -66,6 +73,8 @@ type Detail struct {
 
 // SMITestOptions describes the options for the SMI Test runner
 type SMITestOptions struct {
+	// Ctx is the context of the operation, the test is aborted once it is cancelled.
+	// Defaults to context.Background()
 	Ctx         context.Context
 	OperationID string
 
@@ -75,7 +84,7 @@ type SMITestOptions struct {
 	// Defaults to "meshery"
 	Namespace string
 
-	// Manifest is the remote location of manifest
+	// Manifest is the location of manifest, read with the TemplateResolver of the adapter, e.g. from its Bundle
 	Manifest string
 
 	// Labels is the standard kubernetes labels
@@ -85,105 +94,100 @@ type SMITestOptions struct {
 	Annotations map[string]string
 
 	Kubeconfigs []string
+
+	// FanOut configures how the test runs on the clusters of Kubeconfigs. Defaults to BestEffort.
+	FanOut FanOutOptions
 }
 
-// RunSMITest runs the SMI test on the adapter's service mesh
+// RunSMITest runs the SMI test on the adapter's service mesh, on the cluster of each kubeconfig, see FanOut.
+// The results of each cluster are returned in Response.Clusters, and published in an event tagged with the cluster.
+// The test is aborted once opts.Ctx is cancelled, e.g. when the operation is cancelled.
 func (h *Adapter) RunSMITest(opts SMITestOptions) (Response, error) {
-	e := &meshes.EventsResponse{
-		OperationId: opts.OperationID,
-		Summary:     status.Deploying,
-		Details:     "None",
+	if opts.Ctx == nil {
+		opts.Ctx = context.Background()
 	}
 
 	meshVersion := h.GetVersion()
 	meshType := smp.ServiceMesh_Type(smp.ServiceMesh_Type_value[h.GetName()])
 	name := "smi-conformance"
 
-	test := &SMITest{
-		ctx:         opts.Ctx,
-		id:          opts.OperationID,
-		meshType:    meshType,
-		meshVersion: meshVersion,
-		labels:      opts.Labels,
-		annotations: opts.Annotations,
-	}
-
 	response := Response{
-		ID:                test.id,
+		ID:                opts.OperationID,
 		Date:              time.Now().Format(time.RFC3339),
-		MeshName:          cases.Title(language.Und).String(strings.ReplaceAll(test.meshType.String(), "_", " ")),
-		MeshVersion:       test.meshVersion,
+		MeshName:          cases.Title(language.Und).String(strings.ReplaceAll(meshType.String(), "_", " ")),
+		MeshVersion:       meshVersion,
 		CasesPassed:       "0",
 		PassingPercentage: "0",
 		Status:            "deploying",
 	}
-	var errs []error
-	var wg sync.WaitGroup
-	for _, k8sconfig := range opts.Kubeconfigs {
-		wg.Add(1)
-		go func(k8sconfig string) {
-			defer wg.Done()
-			kClient, err := mesherykube.New([]byte(k8sconfig))
-			if err != nil {
-				errs = append(errs, err)
-				return
-			}
-			if err := test.installConformanceTool(opts.Manifest, opts.Namespace, kClient); err != nil {
-				response.Status = "installing"
-				errs = append(errs, err)
-				return
-			}
-
-			if err := test.connectConformanceTool(name, opts.Namespace, kClient); err != nil {
-				response.Status = "connecting"
-				errs = append(errs, err)
-				return
-			}
-
-			if err := test.runConformanceTest(&response); err != nil {
-				response.Status = "running"
-				errs = append(errs, err)
-				return
-			}
-
-			if err := test.deleteConformanceTool(opts.Manifest, opts.Namespace, kClient); err != nil {
-				response.Status = "deleting"
-				errs = append(errs, err)
-				return
-			}
-		}(k8sconfig)
-	}
-	wg.Wait()
-	if len(errs) != 0 {
-		return response, ErrRunSmi(mergeErrors(errs))
-	}
-	response.Status = "completed"
+	results, err := FanOut(opts.Ctx, opts.Kubeconfigs, opts.FanOut, func(ctx context.Context, cluster Cluster, k8sconfig string) (*ClusterResponse, error) {
+		// Each cluster has its own test and response, as the address of the conformance tool and the results differ.
+		test := &SMITest{
+			ctx:         ctx,
+			id:          opts.OperationID,
+			meshType:    meshType,
+			meshVersion: meshVersion,
+			labels:      opts.Labels,
+			annotations: opts.Annotations,
+			templates:   h.templateResolver(),
+		}
+		clusterResponse := &ClusterResponse{Cluster: cluster.String(), Response: response}
+		r := &clusterResponse.Response
 
-	e.Summary = fmt.Sprintf("Smi conformance test %s successfully", response.Status)
-	jsondata, _ := json.Marshal(response)
-	e.Details = string(jsondata)
-	h.StreamInfo(e)
+		kClient, err := mesherykube.New([]byte(k8sconfig))
+		if err != nil {
+			return clusterResponse, err
+		}
+		if err := test.installConformanceTool(opts.Manifest, opts.Namespace, kClient); err != nil {
+			r.Status = "installing"
+			return clusterResponse, err
+		}
 
-	return response, nil
-}
-func mergeErrors(errs []error) error {
-	if len(errs) == 0 {
-		return nil
-	}
+		if err := test.connectConformanceTool(name, opts.Namespace, kClient); err != nil {
+			r.Status = "connecting"
+			return clusterResponse, err
+		}
+
+		if err := test.runConformanceTest(r); err != nil {
+			r.Status = "running"
+			return clusterResponse, err
+		}
 
-	var errMsgs []string
+		if err := test.deleteConformanceTool(opts.Manifest, opts.Namespace, kClient); err != nil {
+			r.Status = "deleting"
+			return clusterResponse, err
+		}
+		r.Status = "completed"
+
+		jsondata, _ := json.Marshal(r)
+		h.StreamInfo(cluster.Tag(&meshes.EventsResponse{
+			OperationId: opts.OperationID,
+			Summary:     fmt.Sprintf("Smi conformance test %s successfully", r.Status),
+			Details:     string(jsondata),
+		}))
+		return clusterResponse, nil
+	})
 
-	for _, err := range errs {
-		errMsgs = append(errMsgs, err.Error())
+	for _, result := range results {
+		if result.Value != nil {
+			response.Clusters = append(response.Clusters, result.Value)
+		}
+	}
+	if len(response.Clusters) == 1 {
+		single := response.Clusters[0].Response
+		response.CasesPassed, response.PassingPercentage, response.MoreDetails = single.CasesPassed, single.PassingPercentage, single.MoreDetails
 	}
+	if err != nil {
+		return response, ErrRunSmi(err)
+	}
+	response.Status = "completed"
 
-	return fmt.Errorf("%s", strings.Join(errMsgs, "\n"))
+	return response, nil
 }
 
 // installConformanceTool installs the smi conformance tool
 func (test *SMITest) installConformanceTool(smiManifest, ns string, kclient *mesherykube.Client) error {
-	// Fetch the meanifest
-	manifest, err := utils.ReadRemoteFile(smiManifest)
+	manifest, err := test.templates.Resolve(test.ctx, Template(smiManifest))
 	if err != nil {
 		return err
 	}
@@ -192,15 +196,12 @@ func (test *SMITest) installConformanceTool(smiManifest, ns string, kclient *mes
 		return err
 	}
 
-	time.Sleep(20 * time.Second) // Required for all the resources to be created
-
-	return nil
+	return sleep(test.ctx, 20*time.Second) // Required for all the resources to be created
 }
 
 // deleteConformanceTool deletes the smi conformance tool
 func (test *SMITest) deleteConformanceTool(smiManifest, ns string, kclient *mesherykube.Client) error {
-	// Fetch the meanifest
-	manifest, err := utils.ReadRemoteFile(smiManifest)
+	manifest, err := test.templates.Resolve(test.ctx, Template(smiManifest))
 	if err != nil {
 		return err
 	}
@@ -232,7 +233,7 @@ func (test *SMITest) connectConformanceTool(name, ns string, kclient *mesherykub
 
 // runConformanceTest runs the conformance test
 func (test *SMITest) runConformanceTest(response *Response) error {
-	cClient, err := conformance.CreateClient(context.TODO(), test.smiAddress)
+	cClient, err := conformance.CreateClient(test.ctx, test.smiAddress)
 	if err != nil {
 		return err
 	}
@@ -240,8 +241,11 @@ func (test *SMITest) runConformanceTest(response *Response) error {
 	timeout := 100
 	result := &conformance.Response{}
 	for timeout > 0 {
-		time.Sleep(1 * time.Second)
-		result, err = cClient.CClient.RunTest(context.TODO(), &conformance.Request{
+		if err := sleep(test.ctx, 1*time.Second); err != nil {
+			_ = cClient.Close()
+			return err
+		}
+		result, err = cClient.CClient.RunTest(test.ctx, &conformance.Request{
 			Mesh: &smp.ServiceMesh{
 				Annotations: test.annotations,
 				Labels:      test.labels,
@@ -252,6 +256,7 @@ func (test *SMITest) runConformanceTest(response *Response) error {
 		if err == nil {
 			break
 		} else if err != nil && !strings.Contains(err.Error(), "i/o timeout") {
+			_ = cClient.Close()
 			return err
 		}
 		timeout--
@@ -299,3 +304,15 @@ func (test *SMITest) runConformanceTest(response *Response) error {
 
 	return nil
 }
+
+// sleep pauses for the duration d, or until ctx is done, in which case it returns the context's error.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
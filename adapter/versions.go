@@ -0,0 +1,78 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import (
+	"os"
+	"sort"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// MeshModelVersions returns the versions for which meshmodel components are available, i.e. the names of the
+// version directories in MeshmodelComponents, together with the versions loaded during registration.
+func MeshModelVersions() ([]string, error) {
+	versions := make([]string, 0)
+	entries, err := os.ReadDir(MeshmodelComponents)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			versions = append(versions, e.Name())
+		}
+	}
+
+	versionLock.Lock()
+	defer versionLock.Unlock()
+	for v := range AvailableVersions {
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
+
+// SortVersions returns versions sorted semantically in ascending order, without duplicates and empty or "none" versions.
+// Versions that are not semantic versions are sorted lexically, after all others.
+func SortVersions(versions []string) []string {
+	semvers := make([]*semver.Version, 0, len(versions))
+	original := make(map[*semver.Version]string)
+	others := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, v := range versions {
+		if v == "" || v == NoneVersion[0].String() || seen[v] {
+			continue
+		}
+		seen[v] = true
+		sv, err := semver.NewVersion(v)
+		if err != nil {
+			others = append(others, v)
+			continue
+		}
+		semvers = append(semvers, sv)
+		original[sv] = v
+	}
+	sort.Sort(semver.Collection(semvers))
+	sort.Strings(others)
+
+	sorted := make([]string, 0, len(semvers)+len(others))
+	for i, sv := range semvers {
+		// e.g. "v1.8.2" and "1.8.2" denote the same version.
+		if i > 0 && sv.Equal(semvers[i-1]) {
+			continue
+		}
+		sorted = append(sorted, original[sv])
+	}
+	return append(sorted, others...)
+}
@@ -0,0 +1,90 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package adaptertest provides utilities for testing adapters and the code talking to them.
+//
+// NewServer runs the gRPC service of an adapter in-process, on an in-memory listener, and connects a client to it.
+// An EventRecorder records the events published by a handler, and waits for the ones a test expects.
+// NewConfig returns an in-memory configuration, and FakeHandler is a configurable Handler.
+// RunConformance checks that a Handler implementation behaves as the library expects.
+package adaptertest
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshery-adapter-library/api/grpc"
+	"github.com/layer5io/meshery-adapter-library/client"
+	"github.com/layer5io/meshkit/utils/events"
+	ggrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// bufferSize is the size of the in-memory connections between the client and the server.
+const bufferSize = 1 << 20
+
+// Server is a Service running in-process, with a client connected to it.
+type Server struct {
+	Service *grpc.Service
+	Client  *client.AdapterClient
+	// Events records the events published on the EventStreamer of the service, not those published on the
+	// partitions of the meshes it hosts, if any. Use NewEventRecorder to record those.
+	Events *EventRecorder
+}
+
+// NewServer runs the service s on an in-memory listener, and returns it with a client connected to it.
+// An EventStreamer is created if s does not have one. The service is shut down, and the client closed,
+// when the test ends.
+func NewServer(t testing.TB, s *grpc.Service) *Server {
+	t.Helper()
+	if s.EventStreamer == nil {
+		s.EventStreamer = events.NewEventStreamer()
+	}
+	recorder := NewEventRecorder(s.EventStreamer)
+
+	listener := bufconn.Listen(bufferSize)
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- grpc.Serve(ctx, s, listener)
+	}()
+
+	c, err := client.New("passthrough:///bufconn", client.Options{
+		RetryMaxElapsedTime: 5 * time.Second,
+		DialOptions: []ggrpc.DialOption{
+			ggrpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return listener.DialContext(ctx)
+			}),
+		},
+	})
+	if err != nil {
+		cancel()
+		t.Fatalf("connecting to the service: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = c.Close()
+		cancel()
+		if err := <-served; err != nil {
+			t.Errorf("shutting down the service: %v", err)
+		}
+	})
+	return &Server{
+		Service: s,
+		Client:  c,
+		Events:  recorder,
+	}
+}
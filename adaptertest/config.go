@@ -0,0 +1,44 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptertest
+
+import (
+	"testing"
+
+	"github.com/layer5io/meshery-adapter-library/common"
+	"github.com/layer5io/meshery-adapter-library/config"
+	"github.com/layer5io/meshery-adapter-library/config/provider"
+)
+
+// NewConfig returns an in-memory configuration initialized with opts. The server config, mesh spec and
+// operations not given in opts are taken from common.DefaultOpts. The configuration can be used both as
+// the config of a Service and of an adapter.Adapter.
+func NewConfig(t testing.TB, opts provider.Options) config.Handler {
+	t.Helper()
+	if opts.ServerConfig == nil {
+		opts.ServerConfig = common.DefaultOpts.ServerConfig
+	}
+	if opts.MeshSpec == nil {
+		opts.MeshSpec = common.DefaultOpts.MeshSpec
+	}
+	if opts.Operations == nil {
+		opts.Operations = common.DefaultOpts.Operations
+	}
+	cfg, err := provider.NewInMem(opts)
+	if err != nil {
+		t.Fatalf("creating in-memory config: %v", err)
+	}
+	return cfg
+}
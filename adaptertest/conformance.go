@@ -0,0 +1,173 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptertest
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	"github.com/layer5io/meshkit/utils/events"
+)
+
+// DefaultConformanceTimeout is used if ConformanceOptions.Timeout is not set.
+const DefaultConformanceTimeout = 30 * time.Second
+
+// eventDeliveryTime is the time the events published by an operation are given to be recorded, once its first event
+// with its ID has been: events are delivered asynchronously, and in no particular order.
+const eventDeliveryTime = 100 * time.Millisecond
+
+// unknownOperation is the name of an operation no handler is expected to support.
+const unknownOperation = "adaptertest-unknown-operation"
+
+// ConformanceOptions configures RunConformance.
+type ConformanceOptions struct {
+	// EventStreamer is the streamer the handler publishes its events on.
+	EventStreamer *events.EventStreamer
+
+	// Namespace, KubeConfigs, Version and CustomBody are passed to the operations applied.
+	Namespace   string
+	KubeConfigs []string
+	Version     string
+	CustomBody  string
+
+	// Skip lists the operations not to apply, e.g. the ones needing resources the test environment does not provide.
+	Skip []string
+	// Timeout is the time each operation is given to complete and publish its first event. Defaults to DefaultConformanceTimeout.
+	Timeout time.Duration
+}
+
+// RunConformance checks, in subtests of t, that the handler h behaves as the library expects:
+//   - its name is not empty,
+//   - it lists operations with a name, a description and a known category,
+//   - it rejects operations it does not list,
+//   - each operation listed, unless skipped, can be applied and then deleted, and publishes events
+//     with the ID of the operation. Events published without an operation ID fail the check.
+func RunConformance(t *testing.T, h adapter.Handler, opts ConformanceOptions) {
+	if opts.EventStreamer == nil {
+		t.Fatal("ConformanceOptions.EventStreamer is required")
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultConformanceTimeout
+	}
+	recorder := NewEventRecorder(opts.EventStreamer)
+
+	t.Run("name", func(t *testing.T) {
+		if h.GetName() == "" {
+			t.Error("GetName returned an empty name")
+		}
+	})
+
+	ops, err := h.ListOperations()
+	if err != nil {
+		t.Fatalf("ListOperations failed: %v", err)
+	}
+	names := make([]string, 0, len(ops))
+	for name := range ops {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	t.Run("operations", func(t *testing.T) {
+		if len(ops) == 0 {
+			t.Error("ListOperations returned no operations")
+		}
+		for _, name := range names {
+			checkOperationDescriptor(t, name, ops[name])
+		}
+	})
+
+	t.Run("unknown operation", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+		defer cancel()
+		op := opts.operationRequest(unknownOperation, false)
+		if err := h.ApplyOperation(ctx, op); err == nil {
+			t.Errorf("ApplyOperation accepted the unknown operation %q", unknownOperation)
+		}
+	})
+
+	skip := make(map[string]bool, len(opts.Skip))
+	for _, name := range opts.Skip {
+		skip[name] = true
+	}
+	for _, name := range names {
+		name := name
+		t.Run("apply "+name, func(t *testing.T) {
+			if skip[name] {
+				t.Skip("skipped by ConformanceOptions.Skip")
+			}
+			checkApply(t, h, recorder, opts.operationRequest(name, false), opts.Timeout)
+			checkApply(t, h, recorder, opts.operationRequest(name, true), opts.Timeout)
+		})
+	}
+}
+
+func checkOperationDescriptor(t *testing.T, name string, op *adapter.Operation) {
+	t.Helper()
+	switch {
+	case name == "":
+		t.Error("operation listed with an empty name")
+	case op == nil:
+		t.Errorf("operation %q listed without descriptor", name)
+	case op.Description == "":
+		t.Errorf("operation %q has no description", name)
+	}
+	if op == nil {
+		return
+	}
+	if _, ok := meshes.OpCategory_name[op.Type]; !ok {
+		t.Errorf("operation %q has the unknown category %d", name, op.Type)
+	}
+}
+
+// checkApply applies op, and checks that it completes and publishes events with its ID.
+func checkApply(t *testing.T, h adapter.Handler, recorder *EventRecorder, op adapter.OperationRequest, timeout time.Duration) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	before := recorder.Len()
+	if err := h.ApplyOperation(ctx, op); err != nil {
+		t.Fatalf("ApplyOperation %q (delete: %t) failed: %v", op.OperationName, op.IsDeleteOperation, err)
+	}
+	if _, err := recorder.waitForEvent(ctx, before, []EventMatcher{WithOperationID(op.OperationID)}); err != nil {
+		t.Fatalf("ApplyOperation %q (delete: %t) published no event with its operation ID: %v", op.OperationName, op.IsDeleteOperation, err)
+	}
+	// An event published without ID before the one with the ID may still be on its way.
+	deliveryCtx, cancelDelivery := context.WithTimeout(ctx, eventDeliveryTime)
+	defer cancelDelivery()
+	_, _ = recorder.waitForEvent(deliveryCtx, before, []EventMatcher{WithOperationID("")})
+	for _, e := range recorder.Events()[before:] {
+		if e.GetOperationId() == "" {
+			t.Errorf("ApplyOperation %q (delete: %t) published an event without operation ID: %q", op.OperationName, op.IsDeleteOperation, e.GetSummary())
+		}
+	}
+}
+
+func (o *ConformanceOptions) operationRequest(name string, del bool) adapter.OperationRequest {
+	return adapter.OperationRequest{
+		OperationName:     name,
+		Namespace:         o.Namespace,
+		CustomBody:        o.CustomBody,
+		IsDeleteOperation: del,
+		OperationID:       uuid.NewString(),
+		K8sConfigs:        o.KubeConfigs,
+		Version:           o.Version,
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptertest_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshery-adapter-library/adaptertest"
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	"github.com/layer5io/meshkit/utils/events"
+)
+
+// brokenHandlerEnv is set to run the conformance suite on a broken handler, in a child test process.
+const brokenHandlerEnv = "ADAPTERTEST_BROKEN_HANDLER"
+
+func TestRunConformance(t *testing.T) {
+	es := events.NewEventStreamer()
+	h := &adaptertest.FakeHandler{
+		Name:          "fake",
+		EventStreamer: es,
+		Operations: adapter.Operations{
+			"fake_install":    {Type: int32(meshes.OpCategory_INSTALL), Description: "Fake mesh"},
+			"fake_sample_app": {Type: int32(meshes.OpCategory_SAMPLE_APPLICATION), Description: "Fake application"},
+		},
+	}
+	adaptertest.RunConformance(t, h, adaptertest.ConformanceOptions{
+		EventStreamer: es,
+		KubeConfigs:   []string{"kubeconfig"},
+		Timeout:       5 * time.Second,
+	})
+
+	if got, want := len(h.Applied()), 5; got != want {
+		t.Errorf("handler asked to apply %d operations, want %d: the unknown one, and each one applied and deleted", got, want)
+	}
+}
+
+// newBrokenHandler returns a handler with an empty name, an invalid operation descriptor, and publishing an event
+// without operation ID before the event of each operation.
+func newBrokenHandler(es *events.EventStreamer) *adaptertest.FakeHandler {
+	h := &adaptertest.FakeHandler{
+		EventStreamer: es,
+		Operations: adapter.Operations{
+			"broken_install": {Type: int32(meshes.OpCategory_INSTALL)},
+		},
+	}
+	h.ApplyFunc = func(ctx context.Context, op adapter.OperationRequest) error {
+		h.StreamInfo(&meshes.EventsResponse{Summary: "event without operation ID"})
+		h.StreamInfo(&meshes.EventsResponse{OperationId: op.OperationID, Summary: "applied"})
+		return nil
+	}
+	return h
+}
+
+func TestRunConformanceReportsFailures(t *testing.T) {
+	if os.Getenv(brokenHandlerEnv) != "" {
+		es := events.NewEventStreamer()
+		adaptertest.RunConformance(t, newBrokenHandler(es), adaptertest.ConformanceOptions{
+			EventStreamer: es,
+			Timeout:       5 * time.Second,
+		})
+		return
+	}
+
+	// RunConformance fails the test it is given, so it is run on the broken handler in a child process.
+	cmd := exec.Command(os.Args[0], "-test.run=^TestRunConformanceReportsFailures$", "-test.v")
+	cmd.Env = append(os.Environ(), brokenHandlerEnv+"=1")
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("conformance suite passed on a broken handler:\n%s", out)
+	}
+	for _, want := range []string{
+		"--- FAIL: TestRunConformanceReportsFailures/name",
+		"GetName returned an empty name",
+		"--- FAIL: TestRunConformanceReportsFailures/operations",
+		`operation "broken_install" has no description`,
+		"--- FAIL: TestRunConformanceReportsFailures/apply_broken_install",
+		`published an event without operation ID: "event without operation ID"`,
+		"--- PASS: TestRunConformanceReportsFailures/unknown_operation",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("conformance suite output does not contain %q:\n%s", want, out)
+		}
+	}
+}
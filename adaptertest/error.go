@@ -0,0 +1,28 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptertest
+
+import (
+	"github.com/layer5io/meshkit/errors"
+)
+
+const (
+	ErrEventNotReceivedCode = "1000"
+)
+
+// ErrEventNotReceived is returned when no event matching the expectations of a test has been published in time.
+func ErrEventNotReceived(err error) error {
+	return errors.New(ErrEventNotReceivedCode, errors.Alert, []string{"Expected event not received"}, []string{err.Error()}, []string{"The handler did not publish the event, or not in time"}, []string{"Check the events recorded so far, or increase the timeout"})
+}
@@ -0,0 +1,137 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptertest
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	"github.com/layer5io/meshkit/utils/events"
+)
+
+// EventMatcher selects events, see EventRecorder.WaitForEvent.
+type EventMatcher func(*meshes.EventsResponse) bool
+
+// WithOperationID matches the events of the operation with the given ID.
+func WithOperationID(operationID string) EventMatcher {
+	return func(e *meshes.EventsResponse) bool {
+		return e.GetOperationId() == operationID
+	}
+}
+
+// WithEventType matches the events of the given type.
+func WithEventType(t meshes.EventType) EventMatcher {
+	return func(e *meshes.EventsResponse) bool {
+		return e.GetEventType() == t
+	}
+}
+
+// WithSummary matches the events whose summary contains s.
+func WithSummary(s string) EventMatcher {
+	return func(e *meshes.EventsResponse) bool {
+		return strings.Contains(e.GetSummary(), s)
+	}
+}
+
+// WithErrorCode matches the events with the given error code.
+func WithErrorCode(code string) EventMatcher {
+	return func(e *meshes.EventsResponse) bool {
+		return e.GetErrorCode() == code
+	}
+}
+
+// EventRecorder records the events published on an EventStreamer.
+type EventRecorder struct {
+	mx      sync.Mutex
+	events  []*meshes.EventsResponse
+	updated chan struct{} // closed, and replaced, whenever an event is recorded
+}
+
+// NewEventRecorder returns a recorder of the events published on streamer from now on.
+// The recorder keeps receiving events for as long as streamer is in use, as an EventStreamer cannot be unsubscribed from.
+func NewEventRecorder(streamer *events.EventStreamer) *EventRecorder {
+	r := &EventRecorder{updated: make(chan struct{})}
+	ch := make(chan interface{})
+	streamer.Subscribe(ch)
+	go func() {
+		for data := range ch {
+			if e, ok := data.(*meshes.EventsResponse); ok {
+				r.record(e)
+			}
+		}
+	}()
+	return r
+}
+
+func (r *EventRecorder) record(e *meshes.EventsResponse) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.events = append(r.events, e)
+	close(r.updated)
+	r.updated = make(chan struct{})
+}
+
+// Events returns the events recorded so far, in the order they were received.
+func (r *EventRecorder) Events() []*meshes.EventsResponse {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return append([]*meshes.EventsResponse(nil), r.events...)
+}
+
+// Len returns the number of events recorded so far.
+func (r *EventRecorder) Len() int {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return len(r.events)
+}
+
+// WaitForEvent returns the first recorded event matching all matchers, waiting for it to be published if needed.
+// It fails with ErrEventNotReceived if ctx is done before.
+func (r *EventRecorder) WaitForEvent(ctx context.Context, matchers ...EventMatcher) (*meshes.EventsResponse, error) {
+	return r.waitForEvent(ctx, 0, matchers)
+}
+
+// waitForEvent is WaitForEvent, ignoring the first skip events recorded.
+func (r *EventRecorder) waitForEvent(ctx context.Context, skip int, matchers []EventMatcher) (*meshes.EventsResponse, error) {
+	for {
+		r.mx.Lock()
+		for _, e := range r.events[min(skip, len(r.events)):] {
+			if matchAll(e, matchers) {
+				r.mx.Unlock()
+				return e, nil
+			}
+		}
+		skip = len(r.events)
+		updated := r.updated
+		r.mx.Unlock()
+
+		select {
+		case <-updated:
+		case <-ctx.Done():
+			return nil, ErrEventNotReceived(ctx.Err())
+		}
+	}
+}
+
+func matchAll(e *meshes.EventsResponse, matchers []EventMatcher) bool {
+	for _, match := range matchers {
+		if !match(e) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,109 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptertest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	"github.com/layer5io/meshery-adapter-library/status"
+	"github.com/layer5io/meshkit/errors"
+	"github.com/layer5io/meshkit/utils/events"
+)
+
+// FakeHandler is a Handler for tests. By default, it applies the operations it supports by publishing an event,
+// which can be changed with ApplyFunc. It records the operations it is asked to apply.
+type FakeHandler struct {
+	Name          string
+	Operations    adapter.Operations
+	EventStreamer *events.EventStreamer
+
+	// ApplyFunc, if set, is called to apply the supported operations.
+	ApplyFunc func(context.Context, adapter.OperationRequest) error
+	// OAMFunc, if set, is called to process OAM requests.
+	OAMFunc func(context.Context, adapter.OAMRequest) (string, error)
+
+	mx      sync.Mutex
+	applied []adapter.OperationRequest
+}
+
+func (h *FakeHandler) GetName() string {
+	return h.Name
+}
+
+func (h *FakeHandler) GetComponentInfo(interface{}) error {
+	return nil
+}
+
+// ApplyOperation records the operation, and applies it if it is supported.
+func (h *FakeHandler) ApplyOperation(ctx context.Context, op adapter.OperationRequest) error {
+	h.mx.Lock()
+	h.applied = append(h.applied, op)
+	h.mx.Unlock()
+
+	if _, ok := h.Operations[op.OperationName]; !ok {
+		return adapter.ErrOpInvalid
+	}
+	if h.ApplyFunc != nil {
+		return h.ApplyFunc(ctx, op)
+	}
+	result := status.Applied
+	if op.IsDeleteOperation {
+		result = status.Removed
+	}
+	h.StreamInfo(&meshes.EventsResponse{
+		OperationId: op.OperationID,
+		Summary:     fmt.Sprintf("%s %s", op.OperationName, result),
+		Details:     "None",
+	})
+	return nil
+}
+
+func (h *FakeHandler) ListOperations() (adapter.Operations, error) {
+	return h.Operations, nil
+}
+
+func (h *FakeHandler) ProcessOAM(ctx context.Context, req adapter.OAMRequest) (string, error) {
+	if h.OAMFunc != nil {
+		return h.OAMFunc(ctx, req)
+	}
+	return "", nil
+}
+
+func (h *FakeHandler) StreamErr(e *meshes.EventsResponse, err error) {
+	e.EventType = meshes.EventType_ERROR
+	e.Details = err.Error()
+	if _, ok := errors.Is(err); ok {
+		e.ErrorCode = errors.GetCode(err)
+		e.ProbableCause = errors.GetCause(err)
+		e.SuggestedRemediation = errors.GetRemedy(err)
+	}
+	h.EventStreamer.Publish(e)
+}
+
+func (h *FakeHandler) StreamInfo(e *meshes.EventsResponse) {
+	e.EventType = meshes.EventType_INFO
+	h.EventStreamer.Publish(e)
+}
+
+// Applied returns the operations the handler has been asked to apply so far, supported or not.
+func (h *FakeHandler) Applied() []adapter.OperationRequest {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+	return append([]adapter.OperationRequest(nil), h.applied...)
+}
@@ -0,0 +1,154 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+
+	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// Authenticator authenticates the caller of an RPC, based on the metadata and peer information carried by ctx.
+//
+// If Service.Authenticator is set, every RPC is authenticated before it reaches the handler, and the principal
+// is made available to the handler through adapter.PrincipalFromContext.
+type Authenticator interface {
+	Authenticate(ctx context.Context) (*adapter.Principal, error)
+}
+
+// BearerTokenAuthenticator authenticates callers by the token sent in the "authorization: Bearer <token>" metadata.
+type BearerTokenAuthenticator struct {
+	// Tokens maps each accepted token to the name of the principal it authenticates.
+	Tokens map[string]string
+}
+
+// Authenticate implements Authenticator.
+func (a *BearerTokenAuthenticator) Authenticate(ctx context.Context) (*adapter.Principal, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, errors.New("authorization metadata missing")
+	}
+	token, found := strings.CutPrefix(values[0], "Bearer ")
+	if !found {
+		return nil, errors.New("authorization metadata is not a bearer token")
+	}
+
+	// Compare against all tokens, so that the time taken does not depend on which one matches.
+	var name string
+	matched := false
+	for t, n := range a.Tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			name = n
+			matched = true
+		}
+	}
+	if !matched {
+		return nil, errors.New("invalid bearer token")
+	}
+	return &adapter.Principal{Name: name, Method: "bearer"}, nil
+}
+
+// MTLSAuthenticator authenticates callers by the client certificate verified during the TLS handshake,
+// see Service.TLSClientCAFile. The name of the principal is the common name of the certificate,
+// or its first URI SAN, e.g. a SPIFFE ID, if the common name is empty.
+type MTLSAuthenticator struct {
+	// AllowedNames restricts the accepted principals, if not empty.
+	AllowedNames []string
+}
+
+// Authenticate implements Authenticator.
+func (a *MTLSAuthenticator) Authenticate(ctx context.Context) (*adapter.Principal, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, errors.New("peer information missing")
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return nil, errors.New("no verified client certificate")
+	}
+
+	cert := info.State.VerifiedChains[0][0]
+	name := cert.Subject.CommonName
+	if name == "" && len(cert.URIs) > 0 {
+		name = cert.URIs[0].String()
+	}
+	if name == "" {
+		return nil, errors.New("client certificate has neither a common name nor a URI SAN")
+	}
+
+	if len(a.AllowedNames) > 0 {
+		allowed := false
+		for _, n := range a.AllowedNames {
+			if n == name {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return nil, errors.New("client certificate " + name + " is not allowed")
+		}
+	}
+	return &adapter.Principal{Name: name, Method: "mtls"}, nil
+}
+
+// authenticate returns a copy of ctx carrying the authenticated principal.
+func authenticate(ctx context.Context, a Authenticator) (context.Context, error) {
+	p, err := a.Authenticate(ctx)
+	if err != nil {
+		return nil, ErrUnauthenticated(err)
+	}
+	return adapter.ContextWithPrincipal(ctx, p), nil
+}
+
+// authUnaryInterceptor authenticates unary RPCs using a. Health checks are not authenticated,
+// as probes, e.g. the kubelet, cannot provide credentials.
+func authUnaryInterceptor(a Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if strings.HasPrefix(info.FullMethod, "/"+healthpb.Health_ServiceDesc.ServiceName+"/") {
+			return handler(ctx, req)
+		}
+		ctx, err := authenticate(ctx, a)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// authStreamInterceptor authenticates streaming RPCs using a. Health checks are not authenticated.
+func authStreamInterceptor(a Authenticator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if strings.HasPrefix(info.FullMethod, "/"+healthpb.Health_ServiceDesc.ServiceName+"/") {
+			return handler(srv, ss)
+		}
+		ctx, err := authenticate(ss.Context(), a)
+		if err != nil {
+			return err
+		}
+		wrapped := middleware.WrapServerStream(ss)
+		wrapped.WrappedContext = ctx
+		return handler(srv, wrapped)
+	}
+}
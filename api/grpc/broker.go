@@ -0,0 +1,223 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// OverflowPolicy determines what happens to the events published while the buffer of a StreamEvents subscriber is full,
+// i.e. while the client does not keep up with reading them.
+type OverflowPolicy string
+
+const (
+	// DropOldest drops the oldest buffered event to make room for the new one. This is the default.
+	DropOldest OverflowPolicy = "drop-oldest"
+	// DropNewest drops the new event.
+	DropNewest OverflowPolicy = "drop-newest"
+	// Disconnect ends the subscription with codes.ResourceExhausted. The client can then resubscribe.
+	Disconnect OverflowPolicy = "disconnect"
+)
+
+const (
+	// DefaultEventBufferSize is used if Service.EventBufferSize is not set.
+	DefaultEventBufferSize = 100
+	// DefaultEventReplaySize is used if Service.EventReplaySize is not set.
+	DefaultEventReplaySize = 1000
+)
+
+// eventBroker fans out the events published on the adapter's EventStreamer to the StreamEvents subscribers.
+//
+// It holds the only subscription to the EventStreamer, which is drained continuously, and gives every subscriber
+// its own bounded buffer, so that a slow or disconnected client neither blocks nor leaks anything.
+// It numbers the events, and keeps the most recent ones in a ring buffer for replay. Since the EventStreamer delivers
+// every event from a goroutine of its own, events are numbered in the order they are received, which may differ from
+// the order they were published in.
+// The last event of each operation is recorded in the operation tracker.
+type eventBroker struct {
+	instanceID string
+	bufferSize int
+	policy     OverflowPolicy
+	operations *operationTracker
+	dropped    metric.Int64Counter
+
+	mx          sync.Mutex
+	subscribers map[*subscription]struct{}
+	nextSeq     uint64
+	recent      []*meshes.EventsResponse // ring buffer, recent[start] is the oldest event
+	start       int
+	lastEvicted *meshes.EventsResponse
+}
+
+// subscription receives the events matching its filter on events, which is closed if the subscription
+// is ended by the Disconnect policy.
+type subscription struct {
+	events chan *meshes.EventsResponse
+	filter *eventFilter
+}
+
+func newEventBroker(bufferSize, replaySize int, policy OverflowPolicy, operations *operationTracker) *eventBroker {
+	if bufferSize <= 0 {
+		bufferSize = DefaultEventBufferSize
+	}
+	if replaySize <= 0 {
+		replaySize = DefaultEventReplaySize
+	}
+	if policy == "" {
+		policy = DropOldest
+	}
+	// The instrument is a no-op unless the adapter sets up a global MeterProvider.
+	dropped, _ := otel.Meter("github.com/layer5io/meshery-adapter-library/api/grpc").Int64Counter(
+		"meshery.adapter.events.dropped",
+		metric.WithDescription("Number of events not delivered to StreamEvents subscribers that did not keep up"),
+	)
+	return &eventBroker{
+		instanceID:  uuid.NewString(),
+		bufferSize:  bufferSize,
+		policy:      policy,
+		operations:  operations,
+		dropped:     dropped,
+		subscribers: make(map[*subscription]struct{}),
+		nextSeq:     1,
+		recent:      make([]*meshes.EventsResponse, 0, replaySize),
+	}
+}
+
+// subscribe registers a subscriber for the events matching filter. It returns the recent events to replay first,
+// as requested by req, and whether some of them had already been evicted.
+func (b *eventBroker) subscribe(filter *eventFilter, req *meshes.EventsRequest) (*subscription, []*meshes.EventsResponse, bool) {
+	sub := &subscription{
+		events: make(chan *meshes.EventsResponse, b.bufferSize),
+		filter: filter,
+	}
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	replay, truncated := b.replay(filter, req)
+	b.subscribers[sub] = struct{}{}
+	return sub, replay, truncated
+}
+
+// replay returns the recent events matching filter that have been requested for replay by req.
+func (b *eventBroker) replay(filter *eventFilter, req *meshes.EventsRequest) ([]*meshes.EventsResponse, bool) {
+	fromSeq := req.GetReplayFromSequence()
+	since := req.GetReplaySince()
+	if fromSeq == 0 && since == nil {
+		return nil, false
+	}
+
+	truncated := false
+	switch {
+	case fromSeq != 0 && req.GetReplayInstanceId() != "" && req.GetReplayInstanceId() != b.instanceID:
+		// The sequence number has been assigned before a restart, all events published since may have been missed.
+		fromSeq = 1
+		truncated = true
+	case fromSeq != 0:
+		// A sequence number not assigned yet, e.g. one from before a restart of the adapter, cannot be resumed from either.
+		truncated = fromSeq > b.nextSeq || (b.lastEvicted != nil && fromSeq <= b.lastEvicted.Sequence)
+	case b.lastEvicted != nil:
+		truncated = !since.AsTime().After(b.lastEvicted.Timestamp.AsTime())
+	}
+
+	var events []*meshes.EventsResponse
+	for i := range b.recent {
+		e := b.recent[(b.start+i)%len(b.recent)]
+		if fromSeq != 0 && e.Sequence < fromSeq {
+			continue
+		}
+		if fromSeq == 0 && e.Timestamp.AsTime().Before(since.AsTime()) {
+			continue
+		}
+		if filter.match(e, b.operations.owner(e.OperationId)) {
+			events = append(events, e)
+		}
+	}
+	return events, truncated
+}
+
+func (b *eventBroker) unsubscribe(sub *subscription) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	delete(b.subscribers, sub)
+}
+
+// run dispatches the events received from source, tagged with mesh if not empty.
+// An EventStreamer cannot be unsubscribed from, and blocks a goroutine on every event its subscribers do not receive,
+// so source is drained for the lifetime of the service, including after a shutdown, when there are no subscribers left.
+func (b *eventBroker) run(source <-chan interface{}, mesh string) {
+	for data := range source {
+		if e, ok := data.(*meshes.EventsResponse); ok {
+			b.publish(e, mesh)
+		}
+	}
+}
+
+func (b *eventBroker) publish(e *meshes.EventsResponse, mesh string) {
+	// Handlers may reuse the event they published, the subscribers get a copy of their own.
+	event := proto.Clone(e).(*meshes.EventsResponse)
+	event.Timestamp = timestamppb.Now()
+	event.InstanceId = b.instanceID
+	if mesh != "" {
+		event.Mesh = mesh
+	}
+	owner := b.operations.owner(event.OperationId)
+
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	event.Sequence = b.nextSeq
+	b.nextSeq++
+	if len(b.recent) < cap(b.recent) {
+		b.recent = append(b.recent, event)
+	} else {
+		b.lastEvicted = b.recent[b.start]
+		b.recent[b.start] = event
+		b.start = (b.start + 1) % len(b.recent)
+	}
+	b.operations.observe(event)
+
+	for sub := range b.subscribers {
+		if !sub.filter.match(event, owner) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+			continue
+		default:
+		}
+
+		b.dropped.Add(context.Background(), 1, metric.WithAttributes(attribute.String("policy", string(b.policy))))
+		switch b.policy {
+		case DropNewest:
+		case Disconnect:
+			delete(b.subscribers, sub)
+			close(sub.events)
+		default:
+			// Only publish sends on the channel, so there is room for the event once the oldest one is dropped.
+			select {
+			case <-sub.events:
+			default:
+			}
+			sub.events <- event
+		}
+	}
+}
@@ -16,15 +16,33 @@ package grpc
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/layer5io/meshkit/errors"
 )
 
 var (
-	ErrRequestInvalidCode = "1000"
-	ErrPanicCode          = "1001"
-	ErrGrpcListenerCode   = "1002"
-	ErrGrpcServerCode     = "1003"
+	ErrRequestInvalidCode      = "1000"
+	ErrPanicCode               = "1001"
+	ErrGrpcListenerCode        = "1002"
+	ErrGrpcServerCode          = "1003"
+	ErrShutdownTimeoutCode     = "1004"
+	ErrTLSConfigCode           = "1005"
+	ErrUnauthenticatedCode     = "1006"
+	ErrEventOverflowPolicyCode = "1007"
+	ErrSubscriberOverflowCode  = "1008"
+	ErrOperationNotFoundCode   = "1009"
+	ErrUnknownOperationIDCode  = "1010"
+	ErrOperationInProgressCode = "1011"
+	ErrShuttingDownCode        = "1012"
+	ErrOperationCancelledCode  = "1013"
+	ErrOperationFinishedCode   = "1014"
+	ErrRequestValidationCode   = "1015"
+	ErrOperationIDReusedCode   = "1016"
+	ErrMeshNotFoundCode        = "1017"
+	ErrMeshSelectorCode        = "1018"
+	ErrHandlerConfigCode       = "1019"
+	ErrEventsForbiddenCode     = "1020"
 
 	ErrRequestInvalid = errors.New(ErrRequestInvalidCode, errors.Alert, []string{"Apply Request invalid"}, []string{}, []string{}, []string{})
 )
@@ -40,3 +58,84 @@ func ErrGrpcListener(err error) error {
 func ErrGrpcServer(err error) error {
 	return errors.New(ErrGrpcServerCode, errors.Alert, []string{"Error during grpc server initialization"}, []string{err.Error()}, []string{}, []string{})
 }
+
+// ErrShutdownTimeout is returned when operations are still in flight after the shutdown timeout expired.
+func ErrShutdownTimeout(ops []string) error {
+	return errors.New(ErrShutdownTimeoutCode, errors.Alert, []string{"Shutdown timeout expired, in-flight operations were cut off"}, []string{strings.Join(ops, ", ")}, []string{"Operations took longer than the shutdown timeout to complete"}, []string{"Increase the shutdown timeout of the service", "Re-apply the operations that were cut off"})
+}
+
+// ErrTLSConfig is returned when the TLS configuration of the server is invalid.
+func ErrTLSConfig(err error) error {
+	return errors.New(ErrTLSConfigCode, errors.Alert, []string{"Error during grpc server TLS configuration"}, []string{err.Error()}, []string{"Certificate, key or client CA file is missing or invalid"}, []string{"Make sure the TLS files configured for the server exist and are PEM encoded"})
+}
+
+// ErrUnauthenticated is returned when the caller of an RPC cannot be authenticated.
+func ErrUnauthenticated(err error) error {
+	return errors.New(ErrUnauthenticatedCode, errors.Alert, []string{"Request unauthenticated"}, []string{err.Error()}, []string{"Credentials are missing or invalid"}, []string{"Make sure the client sends valid credentials for the authenticator configured in the adapter"})
+}
+
+// ErrEventOverflowPolicy is returned when the event overflow policy of the service is unknown.
+func ErrEventOverflowPolicy(policy OverflowPolicy) error {
+	return errors.New(ErrEventOverflowPolicyCode, errors.Alert, []string{"Invalid event overflow policy"}, []string{fmt.Sprintf("Unknown event overflow policy %q", policy)}, []string{}, []string{"Use one of DropOldest, DropNewest or Disconnect"})
+}
+
+// ErrSubscriberOverflow is returned to a StreamEvents subscriber that is disconnected as it did not keep up with the events.
+var ErrSubscriberOverflow = errors.New(ErrSubscriberOverflowCode, errors.Alert, []string{"Event subscriber disconnected"}, []string{"The client did not keep up with the events published, and its buffer overflowed"}, []string{"The client reads events too slowly"}, []string{"Subscribe again, possibly with filters to reduce the number of events"})
+
+// ErrOperationNotFound is returned when the operation requested is not supported by the adapter.
+func ErrOperationNotFound(name string) error {
+	return errors.New(ErrOperationNotFoundCode, errors.Alert, []string{"Operation not found"}, []string{fmt.Sprintf("Operation %q is not supported by the adapter", name)}, []string{}, []string{"Use one of the operations returned by SupportedOperations"})
+}
+
+// ErrUnknownOperationID is returned when the status of an operation that is not tracked by the adapter is requested.
+func ErrUnknownOperationID(operationID string) error {
+	return errors.New(ErrUnknownOperationIDCode, errors.Alert, []string{"Operation unknown"}, []string{fmt.Sprintf("No operation with ID %q has been applied", operationID)}, []string{"The operation has been applied to another adapter instance, before a restart of the adapter, or too long ago"}, []string{"Use the operation ID returned by ApplyOperation"})
+}
+
+// ErrOperationInProgress is returned when an operation is applied with the ID of an operation that has not finished yet.
+func ErrOperationInProgress(operationID string) error {
+	return errors.New(ErrOperationInProgressCode, errors.Alert, []string{"Operation already in progress"}, []string{fmt.Sprintf("Operation with ID %q has not finished yet", operationID)}, []string{"The operation ID has been reused"}, []string{"Use a unique ID for every operation, or wait for the operation to finish"})
+}
+
+// ErrShuttingDown is returned for queued operations when the server shuts down before they could be started.
+var ErrShuttingDown = errors.New(ErrShuttingDownCode, errors.Alert, []string{"Adapter shutting down"}, []string{"The operation was not started, as the adapter is shutting down"}, []string{}, []string{"Apply the operation again once the adapter has restarted"})
+
+// ErrOperationCancelled is recorded for operations cancelled with CancelOperation.
+func ErrOperationCancelled(operationID string) error {
+	return errors.New(ErrOperationCancelledCode, errors.Alert, []string{"Operation cancelled"}, []string{fmt.Sprintf("Operation with ID %q has been cancelled", operationID)}, []string{"The cancellation of the operation was requested"}, []string{"Apply the operation again if needed, resources it created may have to be cleaned up"})
+}
+
+// ErrOperationFinished is returned when the cancellation of an operation that has already finished is requested.
+func ErrOperationFinished(operationID string) error {
+	return errors.New(ErrOperationFinishedCode, errors.Alert, []string{"Operation already finished"}, []string{fmt.Sprintf("Operation with ID %q has already finished and cannot be cancelled", operationID)}, []string{}, []string{"Use GetOperationStatus to get the outcome of the operation"})
+}
+
+// ErrRequestValidation is returned when an ApplyOperation request fails validation, see Service.Validators.
+func ErrRequestValidation(violations []string) error {
+	return errors.New(ErrRequestValidationCode, errors.Alert, []string{"Apply Request invalid"}, []string{strings.Join(violations, "; ")}, []string{"The request does not match the operations supported by the adapter"}, []string{"Fix the fields reported, using SupportedOperations for the operations, their versions and categories"})
+}
+
+// ErrOperationIDReused is returned when an operation is applied with the ID of a previous operation, but a different request.
+func ErrOperationIDReused(operationID string) error {
+	return errors.New(ErrOperationIDReusedCode, errors.Alert, []string{"Operation ID already used"}, []string{fmt.Sprintf("Operation with ID %q has already been applied with a different request", operationID)}, []string{"The operation ID has been reused for another operation"}, []string{"Use a unique ID for every operation, and the same ID only to retry an operation"})
+}
+
+// ErrMeshNotFound is returned when a request selects a mesh that is not hosted by the adapter.
+func ErrMeshNotFound(mesh string) error {
+	return errors.New(ErrMeshNotFoundCode, errors.Alert, []string{"Mesh not found"}, []string{fmt.Sprintf("Mesh %q is not hosted by the adapter", mesh)}, []string{}, []string{"Use one of the meshes returned by MeshName, or the operations returned by SupportedOperations"})
+}
+
+// ErrMeshSelector is returned when the mesh a request is for cannot be determined.
+func ErrMeshSelector(reason string) error {
+	return errors.New(ErrMeshSelectorCode, errors.Alert, []string{"Invalid mesh selector"}, []string{reason}, []string{"The adapter hosts several meshes"}, []string{"Set the mesh of the request, or use the operation names returned by SupportedOperations"})
+}
+
+// ErrHandlerConfig is returned by Serve when the handlers of the service are not configured.
+func ErrHandlerConfig(reason string) error {
+	return errors.New(ErrHandlerConfigCode, errors.Alert, []string{"Invalid handler configuration"}, []string{reason}, []string{}, []string{"Set Service.Handler, or a Mesh with a Handler for each mesh in Service.Meshes"})
+}
+
+// ErrEventsForbidden is returned when an authenticated user subscribes to the events of another user.
+func ErrEventsForbidden(username string) error {
+	return errors.New(ErrEventsForbiddenCode, errors.Alert, []string{"Events forbidden"}, []string{fmt.Sprintf("Events of user %q cannot be streamed to another user", username)}, []string{"Authenticated users only receive the events of the operations they applied"}, []string{"Remove the other users from the usernames filter of the request"})
+}
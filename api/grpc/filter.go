@@ -0,0 +1,73 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import "github.com/layer5io/meshery-adapter-library/meshes"
+
+// eventFilter selects the events sent to a StreamEvents subscriber, see meshes.EventsRequest.
+type eventFilter struct {
+	operationIDs map[string]bool
+	eventTypes   map[meshes.EventType]bool
+	components   map[string]bool
+	usernames    map[string]bool
+	meshes       map[string]bool
+}
+
+func newEventFilter(req *meshes.EventsRequest) *eventFilter {
+	f := &eventFilter{
+		operationIDs: toSet(req.GetOperationIds()),
+		components:   toSet(req.GetComponents()),
+		usernames:    toSet(req.GetUsernames()),
+		meshes:       toSet(req.GetMeshes()),
+	}
+	if len(req.GetEventTypes()) != 0 {
+		f.eventTypes = make(map[meshes.EventType]bool)
+		for _, t := range req.GetEventTypes() {
+			f.eventTypes[t] = true
+		}
+	}
+	return f
+}
+
+// match reports whether the event e, belonging to an operation applied by owner, passes the filter.
+func (f *eventFilter) match(e *meshes.EventsResponse, owner string) bool {
+	if f.operationIDs != nil && !f.operationIDs[e.OperationId] {
+		return false
+	}
+	if f.eventTypes != nil && !f.eventTypes[e.EventType] {
+		return false
+	}
+	if f.components != nil && !f.components[e.Component] && !f.components[e.ComponentName] {
+		return false
+	}
+	if f.usernames != nil && !f.usernames[owner] {
+		return false
+	}
+	if f.meshes != nil && !f.meshes[e.Mesh] {
+		return false
+	}
+	return true
+}
+
+func toSet(values []string) map[string]bool {
+	if len(values) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
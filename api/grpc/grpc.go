@@ -21,16 +21,24 @@
 package grpc
 
 import (
+	"context"
 	"fmt"
 	"net"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/recovery"
 	"github.com/layer5io/meshery-adapter-library/adapter"
 	"github.com/layer5io/meshery-adapter-library/meshes"
+	"github.com/layer5io/meshkit/errors"
 	"github.com/layer5io/meshkit/utils/events"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 )
 
@@ -44,43 +52,369 @@ type Service struct {
 	StartedAt time.Time `json:"startedat"`
 	TraceURL  string    `json:"traceurl"`
 
-	Handler       adapter.Handler
+	// TLSCertFile and TLSKeyFile are the paths to the PEM encoded server certificate and key.
+	// If set, the server only accepts TLS connections. The files are reloaded when they change.
+	TLSCertFile string `json:"tlscertfile"`
+	TLSKeyFile  string `json:"tlskeyfile"`
+	// TLSClientCAFile is the path to the PEM encoded CA bundle used to verify client certificates (mutual TLS).
+	TLSClientCAFile string `json:"tlsclientcafile"`
+	// TLSClientAuth is either ClientAuthRequire (default) or ClientAuthVerifyIfGiven, and only applies if TLSClientCAFile is set.
+	TLSClientAuth string `json:"tlsclientauth"`
+
+	Handler adapter.Handler
+	// EventStreamer is subscribed to once, by the event broker of the service, which keeps receiving its events for as long
+	// as the process runs, whether the service is serving or not, since subscriptions to it cannot be cancelled.
 	EventStreamer *events.EventStreamer
 
+	// Meshes, if set, are the handlers of several meshes hosted by the service, keyed by mesh name, instead of Handler.
+	// Requests are routed by their mesh selector, or by the prefix of their operation name: operations are listed
+	// prefixed with the name of their mesh, see OperationNameSeparator. The identity reported by ComponentInfo is then
+	// the one of the service, as set by the adapter, rather than the one read from the configuration of a handler.
+	// Serve fails if a mesh has no Handler.
+	Meshes map[string]*Mesh
+
+	// Capabilities are adapter-defined properties reported by ComponentInfo, e.g. supported features.
+	Capabilities map[string]string
+
+	// RedactTemplateURLs hides the location of operation templates returned by SupportedOperations,
+	// e.g. internal hosts or access tokens, by reducing template URLs to their file names.
+	RedactTemplateURLs bool
+
+	// Validators check ApplyOperation requests before they reach the handler, once the operation requested has been
+	// found among the handler's operations. Invalid requests fail with InvalidArgument, and BadRequest details listing
+	// the violations. Defaults to DefaultValidators, append to them to add rules.
+	Validators []Validator
+
+	// Authenticator, if set, authenticates every RPC, except health checks, see Authenticator.
+	Authenticator Authenticator
+
+	// ReadinessChecks drive the status reported by the grpc.health.v1 service, together with the handler's
+	// own health if it implements adapter.HealthChecker.
+	ReadinessChecks []ReadinessCheck
+	// HealthCheckInterval is the interval at which the readiness checks are run. Defaults to DefaultHealthCheckInterval.
+	HealthCheckInterval time.Duration
+
+	// UnaryInterceptors and StreamInterceptors are appended to the server's interceptor chains, after the built-in
+	// tracing, error translation, panic recovery and authentication interceptors. MeshKit errors they return are
+	// translated to gRPC status errors too.
+	UnaryInterceptors  []grpc.UnaryServerInterceptor
+	StreamInterceptors []grpc.StreamServerInterceptor
+
+	// EventBufferSize is the number of events buffered for each StreamEvents subscriber. Defaults to DefaultEventBufferSize.
+	EventBufferSize int
+	// EventOverflowPolicy applies when the buffer of a StreamEvents subscriber is full. Defaults to DropOldest.
+	EventOverflowPolicy OverflowPolicy
+	// EventReplaySize is the number of recent events kept for replay to StreamEvents subscribers. Defaults to DefaultEventReplaySize.
+	EventReplaySize int
+
+	// MaxConcurrentOperations is the maximum number of operations run by the handler at the same time,
+	// further operations are queued. Zero means no limit.
+	MaxConcurrentOperations int
+
+	// OperationTTL is the time finished operations are remembered, to report their status and to deduplicate
+	// ApplyOperation requests retried with the same operation ID. Defaults to DefaultOperationTTL.
+	OperationTTL time.Duration
+
+	// ShutdownTimeout is the time in-flight ApplyOperation and ProcessOAM calls, including asynchronous ones,
+	// are given to complete once a shutdown has been requested, before they are cancelled. Defaults to DefaultShutdownTimeout.
+	ShutdownTimeout time.Duration
+
+	inflight   inflightOperations
+	operations operationTracker
+
+	slotsOnce sync.Once
+	slots     chan struct{}
+
+	brokerOnce sync.Once
+	broker     *eventBroker
+	doneMx     sync.Mutex
+	done       chan struct{} // closed when the server is shutting down, replaced by every Serve
+
 	meshes.UnimplementedMeshServiceServer
 }
 
+// DefaultShutdownTimeout is used if Service.ShutdownTimeout is not set.
+const DefaultShutdownTimeout = 30 * time.Second
+
+// shutdownCancelGrace is the time the operations cut off on shutdown are given to return once cancelled.
+const shutdownCancelGrace = 5 * time.Second
+
+// DefaultOperationTTL is used if Service.OperationTTL is not set.
+const DefaultOperationTTL = time.Hour
+
 // panicHandler is the handler function to handle panic errors.
 func panicHandler(r interface{}) error {
 	fmt.Println("600 Error")
 	return ErrPanic(r)
 }
 
-// Start starts grpc server.
+// Start starts grpc server, and blocks until the process receives SIGINT or SIGTERM (see StartWithContext).
 func Start(s *Service) error {
+	return StartWithContext(context.Background(), s)
+}
+
+// StartWithContext starts grpc server, and blocks until ctx is done or the process receives SIGINT or SIGTERM.
+//
+// On shutdown, the server stops accepting new requests and closes all StreamEvents subscriptions.
+// Queued operations are not started anymore. In-flight ApplyOperation and ProcessOAM calls, including asynchronous ones,
+// are given ShutdownTimeout to complete, after which they are cancelled, given a few more seconds to return,
+// and an error listing them is returned.
+func StartWithContext(ctx context.Context, s *Service) error {
 	address := fmt.Sprintf(":%s", s.Port)
 	listener, err := net.Listen("tcp", address)
 	if err != nil {
 		return ErrGrpcListener(err)
 	}
 
-	server := grpc.NewServer(
-		grpc.ChainUnaryInterceptor(
-			otelgrpc.UnaryServerInterceptor(),
-			recovery.UnaryServerInterceptor(recovery.WithRecoveryHandler(panicHandler)),
-		),
-	)
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return Serve(ctx, s, listener)
+}
+
+// Serve runs grpc server on listener, and blocks until ctx is done, shutting down as described in StartWithContext.
+// Unlike StartWithContext, it does not handle signals, and can therefore run the server in-process, e.g. on
+// an in-memory listener in tests (see package adaptertest). The listener is closed when Serve returns.
+//
+// A Service can be served again once Serve has returned, but not by several Serve calls at the same time.
+// The events and the operations tracked are kept from one run to the next.
+func Serve(ctx context.Context, s *Service, listener net.Listener) error {
+	defer listener.Close()
+
+	switch s.EventOverflowPolicy {
+	case "", DropOldest, DropNewest, Disconnect:
+	default:
+		return ErrEventOverflowPolicy(s.EventOverflowPolicy)
+	}
+	if err := s.checkHandlers(); err != nil {
+		return err
+	}
+
+	if s.StartedAt.IsZero() {
+		s.StartedAt = time.Now()
+	}
+
+	unaryInterceptors := []grpc.UnaryServerInterceptor{
+		otelgrpc.UnaryServerInterceptor(),
+		statusUnaryInterceptor,
+		recovery.UnaryServerInterceptor(recovery.WithRecoveryHandler(panicHandler)),
+	}
+	streamInterceptors := []grpc.StreamServerInterceptor{
+		otelgrpc.StreamServerInterceptor(),
+		statusStreamInterceptor,
+		recovery.StreamServerInterceptor(recovery.WithRecoveryHandler(panicHandler)),
+	}
+	if s.Authenticator != nil {
+		unaryInterceptors = append(unaryInterceptors, authUnaryInterceptor(s.Authenticator))
+		streamInterceptors = append(streamInterceptors, authStreamInterceptor(s.Authenticator))
+	}
+	unaryInterceptors = append(unaryInterceptors, s.UnaryInterceptors...)
+	streamInterceptors = append(streamInterceptors, s.StreamInterceptors...)
+
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+	}
+
+	creds, err := s.serverCredentials()
+	if err != nil {
+		return err
+	}
+	if creds != nil {
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	server := grpc.NewServer(opts...)
 
 	// Reflection is enabled to simplify accessing the gRPC service using gRPCurl, e.g.
 	//    grpcurl --plaintext localhost:10002 meshes.MeshService.SupportedOperations
+	// If TLS is enabled, replace '--plaintext' with '-cacert <ca.crt>', and add '-cert <client.crt> -key <client.key>' for mutual TLS.
 	// If the use of reflection is not desirable, the parameters '-import-path ./meshes/ -proto meshops.proto' have
 	//    to be added to each grpcurl request, with the appropriate import path.
 	reflection.Register(server)
 
 	meshes.RegisterMeshServiceServer(server, s)
 
-	if err = server.Serve(listener); err != nil {
-		return ErrGrpcServer(err)
+	// The adapter is reported as NOT_SERVING until all readiness checks have passed.
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthServer.SetServingStatus(meshes.MeshService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	go s.runHealthChecks(ctx, healthServer)
+
+	// The broker is started right away, so that the last event of every operation is tracked.
+	if s.hasEvents() {
+		s.eventBroker()
+	}
+
+	done := s.startServing()
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		close(done)
+		if err != nil {
+			return ErrGrpcServer(err)
+		}
+		return nil
+	case <-ctx.Done():
+		healthServer.Shutdown()
+		return s.shutdown(server, done)
+	}
+}
+
+// shutdown stops server gracefully, waiting at most ShutdownTimeout for in-flight operations to complete.
+// done is the channel of the run of the service being shut down, see startServing.
+func (s *Service) shutdown(server *grpc.Server, done chan struct{}) error {
+	timeout := s.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = DefaultShutdownTimeout
+	}
+
+	// StreamEvents never returns on its own, GracefulStop would wait for it forever.
+	close(done)
+
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	// Asynchronous operations outlive the calls that started them, so GracefulStop does not wait for them.
+	drained := make(chan struct{})
+	go func() {
+		<-stopped
+		s.inflight.wait()
+		close(drained)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-drained:
+		return nil
+	case <-timer.C:
+	}
+
+	ops := s.inflight.list()
+	// Asynchronous operations run on contexts of their own, which server.Stop does not cancel.
+	s.operations.cancelAll()
+	server.Stop()
+	<-stopped
+	grace := time.NewTimer(shutdownCancelGrace)
+	defer grace.Stop()
+	select {
+	case <-drained:
+	case <-grace.C:
+	}
+	if len(ops) != 0 {
+		return ErrShutdownTimeout(ops)
 	}
 	return nil
 }
+
+// eventBroker returns the broker of the events published on the EventStreamer, and on the partitions of the meshes,
+// starting it on first use.
+func (s *Service) eventBroker() *eventBroker {
+	s.brokerOnce.Do(func() {
+		s.broker = newEventBroker(s.EventBufferSize, s.EventReplaySize, s.EventOverflowPolicy, &s.operations)
+		// Events are tagged with the mesh of their partition, unless the partition is shared.
+		partitions := make(map[*events.EventStreamer]string)
+		for name, m := range s.Meshes {
+			if m == nil || m.EventStreamer == nil || m.EventStreamer == s.EventStreamer {
+				continue
+			}
+			if _, shared := partitions[m.EventStreamer]; shared {
+				name = ""
+			}
+			partitions[m.EventStreamer] = name
+		}
+		if s.EventStreamer != nil {
+			partitions[s.EventStreamer] = ""
+		}
+		for streamer, mesh := range partitions {
+			source := make(chan interface{}, s.broker.bufferSize)
+			streamer.Subscribe(source)
+			go s.broker.run(source, mesh)
+		}
+	})
+	return s.broker
+}
+
+// operationTTL returns the time finished operations are remembered.
+func (s *Service) operationTTL() time.Duration {
+	if s.OperationTTL > 0 {
+		return s.OperationTTL
+	}
+	return DefaultOperationTTL
+}
+
+// acquireSlot waits until fewer than MaxConcurrentOperations operations are running, and returns the function
+// to call once the operation has completed. It fails if ctx is done or the server shuts down in the meantime.
+func (s *Service) acquireSlot(ctx context.Context) (func(), error) {
+	if s.MaxConcurrentOperations <= 0 {
+		return func() {}, nil
+	}
+	s.slotsOnce.Do(func() {
+		s.slots = make(chan struct{}, s.MaxConcurrentOperations)
+	})
+	select {
+	case s.slots <- struct{}{}:
+		return func() { <-s.slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-s.shuttingDown():
+		return nil, ErrShuttingDown
+	}
+}
+
+// runOperation runs the operation with the given ID once a slot is free, and records its progress in the tracker.
+// If the operation has been cancelled, a terminal event is published, and ErrOperationCancelled is returned.
+// An operation that never started, unless cancelled, is not tracked anymore, see operationTracker.discard.
+func (s *Service) runOperation(ctx context.Context, mesh, operationID, name string, run func(context.Context) error) error {
+	release, err := s.acquireSlot(ctx)
+	if err == nil {
+		defer release()
+		s.operations.start(operationID)
+		err = run(ctx)
+	} else if s.operations.discard(operationID, err) {
+		// The operation never started, e.g. because the server is shutting down, it can be applied again with the same ID.
+		return err
+	}
+	if s.operations.finish(operationID, err) == meshes.OperationState_CANCELLED {
+		err = ErrOperationCancelled(operationID)
+		if streamer := s.eventStreamer(mesh); streamer != nil {
+			streamer.Publish(&meshes.EventsResponse{
+				EventType:            meshes.EventType_WARN,
+				OperationId:          operationID,
+				Summary:              fmt.Sprintf("Operation %s cancelled", name),
+				Details:              err.Error(),
+				ErrorCode:            ErrOperationCancelledCode,
+				ProbableCause:        errors.GetCause(err),
+				SuggestedRemediation: errors.GetRemedy(err),
+			})
+		}
+	}
+	return err
+}
+
+// startServing starts a new run of the service, and returns the channel to close when it shuts down.
+func (s *Service) startServing() chan struct{} {
+	s.doneMx.Lock()
+	defer s.doneMx.Unlock()
+	s.done = make(chan struct{})
+	return s.done
+}
+
+// shuttingDown returns a channel that is closed when the current run of the server is shutting down.
+func (s *Service) shuttingDown() <-chan struct{} {
+	s.doneMx.Lock()
+	defer s.doneMx.Unlock()
+	if s.done == nil {
+		s.done = make(chan struct{})
+	}
+	return s.done
+}
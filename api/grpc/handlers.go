@@ -15,22 +15,40 @@
 package grpc
 
 import (
-	"time"
+	"fmt"
+	"strconv"
+	"strings"
 
+	"github.com/google/uuid"
 	"github.com/layer5io/meshery-adapter-library/adapter"
 	"github.com/layer5io/meshery-adapter-library/meshes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
 
 	"context"
 )
 
 // MeshName is the handler function for the method MeshName.
+// If the service hosts several meshes, their names are returned, sorted and comma separated.
 func (s *Service) MeshName(ctx context.Context, req *meshes.MeshNameRequest) (*meshes.MeshNameResponse, error) {
+	if names := s.meshNames(); names != nil {
+		return &meshes.MeshNameResponse{
+			Name: strings.Join(names, ","),
+		}, nil
+	}
 	return &meshes.MeshNameResponse{
 		Name: s.Handler.GetName(),
 	}, nil
 }
 
 // ApplyOperation is the handler function for the method ApplyOperation.
+// If the request is asynchronous, it returns as soon as the operation has been accepted, with its ID,
+// and the progress of the operation can be queried with GetOperationStatus.
+//
+// Operations with an ID are idempotent: applying the same request again with the same ID, e.g. to retry it after
+// a timeout, does not run it again, but waits for the original operation to finish, or returns its result if it has
+// finished already. Reusing an ID for a different request fails. IDs are remembered for OperationTTL after the operation
+// has finished. Operations with an ID run on even if the call is cancelled, until they are cancelled with CancelOperation.
 func (s *Service) ApplyOperation(ctx context.Context, req *meshes.ApplyRuleRequest) (*meshes.ApplyRuleResponse, error) {
 	// TODO: if err is nil then the response is correctly propagated to the client as JSON
 	// TODO: Consider whether this is the correct way to handle errors.
@@ -40,6 +58,20 @@ func (s *Service) ApplyOperation(ctx context.Context, req *meshes.ApplyRuleReque
 			OperationId: "",
 		}, ErrRequestInvalid
 	}
+	mesh, handler, opName, err := s.route(req.Mesh, req.OpName)
+	if err != nil {
+		return operationResponse(req.OperationId, err)
+	}
+	// Requests are identified by the mesh and the operation they are for, however these are selected.
+	req = proto.Clone(req).(*meshes.ApplyRuleRequest)
+	req.Mesh = mesh
+	req.OpName = opName
+	if err := s.validate(ctx, req, handler); err != nil {
+		return &meshes.ApplyRuleResponse{
+			Error:       err.Error(),
+			OperationId: req.OperationId,
+		}, err
+	}
 
 	operation := adapter.OperationRequest{
 		OperationName:     req.OpName,
@@ -50,100 +82,257 @@ func (s *Service) ApplyOperation(ctx context.Context, req *meshes.ApplyRuleReque
 		OperationID:       req.OperationId,
 		K8sConfigs:        req.KubeConfigs,
 		Version:           req.Version,
+		Mesh:              req.Mesh,
+		Values:            req.Values,
+	}
+	if p, ok := adapter.PrincipalFromContext(ctx); ok {
+		operation.Username = p.Name
+	}
+	if req.Async && operation.OperationID == "" {
+		operation.OperationID = uuid.NewString()
+	}
+
+	name := qualifiedOperationName(mesh, operation.OperationName)
+	desc := fmt.Sprintf("ApplyOperation %s (operation ID: %s)", name, operation.OperationID)
+	apply := func(ctx context.Context) error {
+		return handler.ApplyOperation(ctx, operation)
+	}
+	if operation.OperationID == "" {
+		// Without ID, the operation can neither be retried nor cancelled, and runs within the call.
+		defer s.inflight.add(desc)()
+		err := s.runOperation(ctx, mesh, "", name, apply)
+		return operationResponse("", err)
+	}
+
+	// The operation outlives the call, but keeps the values of its context, e.g. the principal and the trace.
+	opCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	hash := requestHash(req, operation.Username)
+	tracked, duplicate, err := s.operations.add(operation.OperationID, name, operation.Username, hash, s.operationTTL(), cancel)
+	if err != nil {
+		cancel()
+		return operationResponse(operation.OperationID, err)
+	}
+	if duplicate {
+		cancel()
+	} else {
+		release := s.inflight.add(desc)
+		go func() {
+			defer release()
+			defer cancel()
+			_ = s.runOperation(opCtx, mesh, operation.OperationID, name, apply)
+		}()
+	}
+	if req.Async {
+		return operationResponse(operation.OperationID, nil)
+	}
+
+	select {
+	case <-tracked.done:
+	case <-ctx.Done():
+		return operationResponse(operation.OperationID, ctx.Err())
 	}
-	err := s.Handler.ApplyOperation(ctx, operation)
+	return operationResponse(operation.OperationID, s.operations.result(tracked))
+}
+
+// operationResponse returns the response of ApplyOperation for the operation with the given ID, failed with err if not nil.
+func operationResponse(operationID string, err error) (*meshes.ApplyRuleResponse, error) {
 	if err != nil {
 		return &meshes.ApplyRuleResponse{
 			Error:       err.Error(),
-			OperationId: req.OperationId,
+			OperationId: operationID,
 		}, err
 	}
-
 	return &meshes.ApplyRuleResponse{
 		Error:       "",
-		OperationId: req.OperationId,
+		OperationId: operationID,
 	}, nil
 }
 
+// GetOperationStatus is the handler function for the method GetOperationStatus.
+// Operations are tracked from the moment they are applied, whether synchronously or asynchronously, until
+// long after they have finished. Note that an operation has succeeded as soon as the handler returned without error,
+// handlers that complete operations in the background report their outcome with events, see last_event.
+// Only the user that applied an operation can query it.
+func (s *Service) GetOperationStatus(ctx context.Context, req *meshes.OperationStatusRequest) (*meshes.OperationStatusResponse, error) {
+	if req.GetOperationId() == "" {
+		return nil, ErrRequestInvalid
+	}
+	if p, ok := adapter.PrincipalFromContext(ctx); ok && s.operations.owner(req.GetOperationId()) != p.Name {
+		return nil, ErrUnknownOperationID(req.GetOperationId())
+	}
+	resp, ok := s.operations.status(req.GetOperationId())
+	if !ok {
+		return nil, ErrUnknownOperationID(req.GetOperationId())
+	}
+	return resp, nil
+}
+
+// CancelOperation is the handler function for the method CancelOperation.
+// It cancels the context of a queued or running operation. The operation is recorded as cancelled, and a terminal
+// event is published, once the handler has returned with an error. Only the user that applied an operation can cancel it.
+func (s *Service) CancelOperation(ctx context.Context, req *meshes.CancelOperationRequest) (*meshes.CancelOperationResponse, error) {
+	operationID := req.GetOperationId()
+	if operationID == "" {
+		return nil, ErrRequestInvalid
+	}
+	if p, ok := adapter.PrincipalFromContext(ctx); ok && s.operations.owner(operationID) != p.Name {
+		return nil, ErrUnknownOperationID(operationID)
+	}
+	switch found, active := s.operations.cancelOperation(operationID); {
+	case !found:
+		return nil, ErrUnknownOperationID(operationID)
+	case !active:
+		return nil, ErrOperationFinished(operationID)
+	}
+	return &meshes.CancelOperationResponse{}, nil
+}
+
 // SupportedOperations is the handler function for the method SupportedOperations.
+// It returns the complete descriptors of the operations, ordered by key. Template URLs are reduced to their
+// file names if RedactTemplateURLs is set.
 func (s *Service) SupportedOperations(ctx context.Context, req *meshes.SupportedOperationsRequest) (*meshes.SupportedOperationsResponse, error) {
-	result, err := s.Handler.ListOperations()
+	result, err := s.listOperations()
 	if err != nil {
 		return nil, err
 	}
 
-	operations := make([]*meshes.SupportedOperation, 0)
-	for key, val := range result {
-		operations = append(operations, &meshes.SupportedOperation{
-			Key:      key,
-			Value:    val.Description,
-			Category: meshes.OpCategory(val.Type),
-		})
-	}
-
 	return &meshes.SupportedOperationsResponse{
-		Ops:   operations,
+		Ops:   s.supportedOperations(result),
 		Error: "none",
 	}, nil
 }
 
 // StreamEvents is the handler function for the method StreamEvents.
-func (s *Service) StreamEvents(ctx *meshes.EventsRequest, srv meshes.MeshService_StreamEventsServer) error {
-	clientchan := make(chan interface{}, 10)
-	go s.EventStreamer.Subscribe(clientchan)
-	for {
-		data := <-clientchan
-		event := &meshes.EventsResponse{
-			OperationId:          data.(*meshes.EventsResponse).OperationId,
-			EventType:            meshes.EventType(data.(*meshes.EventsResponse).EventType),
-			Summary:              data.(*meshes.EventsResponse).Summary,
-			Details:              data.(*meshes.EventsResponse).Details,
-			ErrorCode:            data.(*meshes.EventsResponse).ErrorCode,
-			ProbableCause:        data.(*meshes.EventsResponse).ProbableCause,
-			SuggestedRemediation: data.(*meshes.EventsResponse).SuggestedRemediation,
-			Component:            data.(*meshes.EventsResponse).Component,
-			ComponentName:        data.(*meshes.EventsResponse).ComponentName,
+// Only the events matching the filters in the request are sent, starting with the recent events requested for replay, if any.
+// Whether some of them had already been evicted is sent in the header ReplayTruncatedHeader, and on the first event.
+// The subscription ends when the client goes away or the server shuts down.
+// Authenticated users only receive the events of the operations they applied.
+func (s *Service) StreamEvents(req *meshes.EventsRequest, srv meshes.MeshService_StreamEventsServer) error {
+	if p, ok := adapter.PrincipalFromContext(srv.Context()); ok {
+		for _, username := range req.GetUsernames() {
+			if username != p.Name {
+				return ErrEventsForbidden(username)
+			}
+		}
+		req.Usernames = []string{p.Name}
+	}
+	broker := s.eventBroker()
+	sub, replay, truncated := broker.subscribe(newEventFilter(req), req)
+	defer broker.unsubscribe(sub)
+
+	// The header tells the client whether events were missed even if no event follows, and that it is subscribed.
+	if err := srv.SendHeader(metadata.Pairs(meshes.ReplayTruncatedHeader, strconv.FormatBool(truncated))); err != nil {
+		return err
+	}
+
+	send := func(event *meshes.EventsResponse) error {
+		if truncated {
+			// Events are shared among subscribers, the flag is only set on this subscriber's copy.
+			event = proto.Clone(event).(*meshes.EventsResponse)
+			event.ReplayTruncated = true
+			truncated = false
 		}
+		return srv.Send(event)
+	}
 
-		if err := srv.Send(event); err != nil {
+	for _, event := range replay {
+		if err := send(event); err != nil {
 			return err
 		}
-		time.Sleep(500 * time.Millisecond)
+	}
+
+	for {
+		select {
+		case event, ok := <-sub.events:
+			if !ok {
+				return ErrSubscriberOverflow
+			}
+			if err := send(event); err != nil {
+				return err
+			}
+		case <-srv.Context().Done():
+			return nil
+		case <-s.shuttingDown():
+			return nil
+		}
 	}
 }
 
-// ProcessOAM is the handler function for the method ProcessOAM
+// ProcessOAM is the handler function for the method ProcessOAM.
+// If the request has an operation ID, the operation can be queried with GetOperationStatus and cancelled with CancelOperation.
 func (s *Service) ProcessOAM(ctx context.Context, srv *meshes.ProcessOAMRequest) (*meshes.ProcessOAMResponse, error) {
+	defer s.inflight.add(fmt.Sprintf("ProcessOAM (user: %s, operation ID: %s)", srv.Username, srv.OperationId))()
+
+	mesh, handler, _, err := s.route(srv.Mesh, "")
+	if err != nil {
+		return nil, err
+	}
+	name := qualifiedOperationName(mesh, "ProcessOAM")
 	operation := adapter.OAMRequest{
-		Username:   srv.Username,
-		DeleteOp:   srv.DeleteOp,
-		OamComps:   srv.OamComps,
-		OamConfig:  srv.OamConfig,
-		K8sConfigs: srv.KubeConfigs,
+		Username:    srv.Username,
+		DeleteOp:    srv.DeleteOp,
+		OamComps:    srv.OamComps,
+		OamConfig:   srv.OamConfig,
+		K8sConfigs:  srv.KubeConfigs,
+		OperationID: srv.OperationId,
+		Mesh:        mesh,
+	}
+	if p, ok := adapter.PrincipalFromContext(ctx); ok {
+		operation.Username = p.Name
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if operation.OperationID != "" {
+		if _, _, err := s.operations.add(operation.OperationID, name, operation.Username, "", s.operationTTL(), cancel); err != nil {
+			return nil, err
+		}
 	}
 
-	msg, err := s.Handler.ProcessOAM(ctx, operation)
+	var msg string
+	err = s.runOperation(ctx, mesh, operation.OperationID, name, func(ctx context.Context) error {
+		var err error
+		msg, err = handler.ProcessOAM(ctx, operation)
+		return err
+	})
 	return &meshes.ProcessOAMResponse{Message: msg}, err
 }
 
-// ProcessOAM is the handler function for the method ProcessOAM
-func (s *Service) MeshVersions(context.Context, *meshes.MeshVersionsRequest) (*meshes.MeshVersionsResponse, error) {
-	versions := make([]string, 0)
+// MeshVersions is the handler function for the method MeshVersions.
+// It returns the mesh versions the adapter can install, merged from the available meshmodel components,
+// the configured mesh version and the versions of the operations, and sorted semantically.
+// If an operation name is given, only the versions of this operation are returned.
+func (s *Service) MeshVersions(ctx context.Context, req *meshes.MeshVersionsRequest) (*meshes.MeshVersionsResponse, error) {
+	ops, err := s.listOperations()
+	if err != nil {
+		return nil, err
+	}
+	versions, err := s.meshVersions(ops, req.GetOperationName())
+	if err != nil {
+		return nil, err
+	}
 	return &meshes.MeshVersionsResponse{
 		Version: versions,
 	}, nil
 }
 
-// ProcessOAM is the handler function for the method ProcessOAM
+// ComponentInfo is the handler function for the method ComponentInfo.
+// Besides the component's identity, it reports its build information, uptime and capabilities as properties.
 func (s *Service) ComponentInfo(context.Context, *meshes.ComponentInfoRequest) (*meshes.ComponentInfoResponse, error) {
-	err := s.Handler.GetComponentInfo(s)
+	if len(s.Meshes) == 0 {
+		if err := s.Handler.GetComponentInfo(s); err != nil {
+			return nil, err
+		}
+	}
+	properties, err := s.componentProperties()
 	if err != nil {
 		return nil, err
 	}
 	return &meshes.ComponentInfoResponse{
-		Type:    s.Type,
-		Name:    s.Name,
-		Version: s.Version,
-		GitSha:  s.GitSHA,
+		Type:       s.Type,
+		Name:       s.Name,
+		Version:    s.Version,
+		GitSha:     s.GitSHA,
+		Properties: properties,
 	}, nil
 }
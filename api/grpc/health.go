@@ -0,0 +1,155 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshery-adapter-library/config"
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// DefaultHealthCheckInterval is used if Service.HealthCheckInterval is not set.
+const DefaultHealthCheckInterval = 10 * time.Second
+
+// ReadinessCheck is a named readiness signal of the adapter. The adapter is reported as SERVING
+// by the grpc.health.v1 service only when all of its readiness checks pass.
+type ReadinessCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// ConfigLoadedCheck checks that the server and mesh configuration can be read from cfg.
+func ConfigLoadedCheck(cfg config.Handler) ReadinessCheck {
+	return ReadinessCheck{
+		Name: "config",
+		Check: func(ctx context.Context) error {
+			server := make(map[string]string)
+			if err := cfg.GetObject(adapter.ServerKey, &server); err != nil {
+				return err
+			}
+			spec := &adapter.Spec{}
+			return cfg.GetObject(adapter.MeshSpecKey, spec)
+		},
+	}
+}
+
+// MeshModelRegisteredCheck checks that the meshmodel components have been registered, see adapter.RegisterMeshModelComponents.
+func MeshModelRegisteredCheck() ReadinessCheck {
+	return ReadinessCheck{
+		Name: "meshmodel",
+		Check: func(ctx context.Context) error {
+			state, err := adapter.MeshModelRegistrationState()
+			if err != nil {
+				return err
+			}
+			if state != adapter.MeshModelRegistrationCompleted {
+				return fmt.Errorf("meshmodel components registration %s", state)
+			}
+			return nil
+		},
+	}
+}
+
+// KubernetesCheck checks that the API servers of the Kubernetes clusters returned by kubeconfigs are reachable.
+func KubernetesCheck(kubeconfigs func() ([]string, error)) ReadinessCheck {
+	return ReadinessCheck{
+		Name: "kubernetes",
+		Check: func(ctx context.Context) error {
+			configs, err := kubeconfigs()
+			if err != nil {
+				return err
+			}
+			for _, k8sconfig := range configs {
+				kClient, err := mesherykube.New([]byte(k8sconfig))
+				if err != nil {
+					return err
+				}
+				if err := kClient.KubeClient.Discovery().RESTClient().Get().AbsPath("/readyz").Do(ctx).Error(); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// readinessChecks returns the configured readiness checks, and the handlers' own health checks if they implement adapter.HealthChecker.
+func (s *Service) readinessChecks() []ReadinessCheck {
+	checks := append([]ReadinessCheck{}, s.ReadinessChecks...)
+	handlers := s.handlers()
+	names := make([]string, 0, len(handlers))
+	for mesh := range handlers {
+		names = append(names, mesh)
+	}
+	sort.Strings(names)
+	for _, mesh := range names {
+		hc, ok := handlers[mesh].(adapter.HealthChecker)
+		if !ok {
+			continue
+		}
+		name := "handler"
+		if mesh != "" {
+			name += " " + mesh
+		}
+		checks = append(checks, ReadinessCheck{Name: name, Check: hc.CheckHealth})
+	}
+	return checks
+}
+
+// checkReadiness runs all readiness checks, and returns the name and error of the failed ones.
+func (s *Service) checkReadiness(ctx context.Context, timeout time.Duration) map[string]error {
+	failed := make(map[string]error)
+	for _, c := range s.readinessChecks() {
+		cctx, cancel := context.WithTimeout(ctx, timeout)
+		if err := c.Check(cctx); err != nil {
+			failed[c.Name] = err
+		}
+		cancel()
+	}
+	return failed
+}
+
+// runHealthChecks updates the status of the health server from the readiness checks, until ctx is done.
+func (s *Service) runHealthChecks(ctx context.Context, hs *health.Server) {
+	interval := s.HealthCheckInterval
+	if interval <= 0 {
+		interval = DefaultHealthCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		status := healthpb.HealthCheckResponse_SERVING
+		if failed := s.checkReadiness(ctx, interval); len(failed) != 0 {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		hs.SetServingStatus("", status)
+		hs.SetServingStatus(meshes.MeshService_ServiceDesc.ServiceName, status)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
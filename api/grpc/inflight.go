@@ -0,0 +1,71 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"sort"
+	"sync"
+)
+
+// inflightOperations keeps track of the operations currently processed by the handler,
+// so that a shutdown can wait for them, and report the ones cut off.
+type inflightOperations struct {
+	mx   sync.Mutex
+	next uint64
+	ops  map[uint64]string
+	wg   sync.WaitGroup
+}
+
+// add registers an operation described by desc, and returns the function to call once it has completed.
+func (o *inflightOperations) add(desc string) func() {
+	o.mx.Lock()
+	defer o.mx.Unlock()
+	if o.ops == nil {
+		o.ops = make(map[uint64]string)
+	}
+	id := o.next
+	o.next++
+	o.ops[id] = desc
+	o.wg.Add(1)
+
+	return func() {
+		o.mx.Lock()
+		defer o.mx.Unlock()
+		delete(o.ops, id)
+		o.wg.Done()
+	}
+}
+
+// wait blocks until all operations in flight have completed.
+func (o *inflightOperations) wait() {
+	o.wg.Wait()
+}
+
+// list returns the descriptions of all operations in flight, in the order they were started.
+func (o *inflightOperations) list() []string {
+	o.mx.Lock()
+	defer o.mx.Unlock()
+	ids := make([]uint64, 0, len(o.ops))
+	for id := range o.ops {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	descs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		descs = append(descs, o.ops[id])
+	}
+	return descs
+}
@@ -0,0 +1,199 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"net/url"
+	"path"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshery-adapter-library/meshes"
+)
+
+// Keys of the properties reported by ComponentInfo. Adapter capabilities are added with the prefix PropertyCapabilityPrefix.
+const (
+	PropertyStartedAt             = "started_at"
+	PropertyUptime                = "uptime"
+	PropertyGoVersion             = "go_version"
+	PropertyModulePath            = "module_path"
+	PropertyModuleVersion         = "module_version"
+	PropertyLibraryVersion        = "library_version"
+	PropertyVCSRevision           = "vcs_revision"
+	PropertyVCSTime               = "vcs_time"
+	PropertyVCSModified           = "vcs_modified"
+	PropertyOperationCategories   = "operation_categories"
+	PropertyMeshVersions          = "mesh_versions"
+	PropertyMeshModelRegistration = "meshmodel_registration"
+	PropertyCapabilityPrefix      = "capability."
+)
+
+const libraryModulePath = "github.com/layer5io/meshery-adapter-library"
+
+// meshVersions returns the versions of the operation named operationName, or all mesh versions the adapter can install
+// if operationName is empty, see MeshVersions.
+func (s *Service) meshVersions(ops adapter.Operations, operationName string) ([]string, error) {
+	versions := make([]string, 0)
+	if operationName != "" {
+		op, ok := ops[operationName]
+		if !ok || op == nil {
+			return nil, ErrOperationNotFound(operationName)
+		}
+		for _, v := range op.Versions {
+			versions = append(versions, v.String())
+		}
+		return adapter.SortVersions(versions), nil
+	}
+
+	mmVersions, err := adapter.MeshModelVersions()
+	if err != nil {
+		return nil, err
+	}
+	versions = append(versions, mmVersions...)
+	for _, h := range s.handlers() {
+		if h, ok := h.(interface{ GetVersion() string }); ok {
+			versions = append(versions, h.GetVersion())
+		}
+	}
+	for _, op := range ops {
+		if op == nil {
+			continue
+		}
+		for _, v := range op.Versions {
+			versions = append(versions, v.String())
+		}
+	}
+	return adapter.SortVersions(versions), nil
+}
+
+// componentProperties returns the properties reported by ComponentInfo. Lists are comma separated.
+func (s *Service) componentProperties() (map[string]string, error) {
+	properties := map[string]string{
+		PropertyGoVersion: runtime.Version(),
+	}
+
+	if !s.StartedAt.IsZero() {
+		properties[PropertyStartedAt] = s.StartedAt.UTC().Format(time.RFC3339)
+		properties[PropertyUptime] = time.Since(s.StartedAt).Round(time.Second).String()
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		properties[PropertyModulePath] = info.Main.Path
+		properties[PropertyModuleVersion] = info.Main.Version
+		for _, dep := range info.Deps {
+			if dep.Path == libraryModulePath {
+				properties[PropertyLibraryVersion] = dep.Version
+			}
+		}
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				properties[PropertyVCSRevision] = setting.Value
+			case "vcs.time":
+				properties[PropertyVCSTime] = setting.Value
+			case "vcs.modified":
+				properties[PropertyVCSModified] = setting.Value
+			}
+		}
+	}
+
+	ops, err := s.listOperations()
+	if err != nil {
+		return nil, err
+	}
+	categories := make(map[string]bool)
+	for _, op := range ops {
+		if op == nil {
+			continue
+		}
+		categories[meshes.OpCategory(op.Type).String()] = true
+	}
+	names := make([]string, 0, len(categories))
+	for c := range categories {
+		names = append(names, c)
+	}
+	sort.Strings(names)
+	properties[PropertyOperationCategories] = strings.Join(names, ",")
+
+	versions, err := s.meshVersions(ops, "")
+	if err != nil {
+		return nil, err
+	}
+	properties[PropertyMeshVersions] = strings.Join(versions, ",")
+
+	state, err := adapter.MeshModelRegistrationState()
+	if err != nil {
+		state = state + ": " + err.Error()
+	}
+	properties[PropertyMeshModelRegistration] = state
+
+	for k, v := range s.Capabilities {
+		properties[PropertyCapabilityPrefix+k] = v
+	}
+	return properties, nil
+}
+
+// supportedOperations returns the complete descriptors of the operations, ordered by key, see SupportedOperations.
+func (s *Service) supportedOperations(ops adapter.Operations) []*meshes.SupportedOperation {
+	keys := make([]string, 0, len(ops))
+	for key, op := range ops {
+		if op != nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	operations := make([]*meshes.SupportedOperation, 0, len(keys))
+	for _, key := range keys {
+		op := ops[key]
+		operation := &meshes.SupportedOperation{
+			Key:                  key,
+			Value:                op.Description,
+			Category:             meshes.OpCategory(op.Type),
+			AdditionalProperties: op.AdditionalProperties,
+		}
+		for _, v := range op.Versions {
+			operation.Versions = append(operation.Versions, v.String())
+		}
+		for _, t := range op.Templates {
+			template := string(t)
+			if s.RedactTemplateURLs {
+				template = redactTemplateURL(template)
+			}
+			operation.Templates = append(operation.Templates, template)
+		}
+		for _, svc := range op.Services {
+			operation.Services = append(operation.Services, string(svc))
+		}
+		operations = append(operations, operation)
+	}
+	return operations
+}
+
+// redactTemplateURL reduces a template URL or path to its file name, leaving other templates, e.g. "none", untouched.
+func redactTemplateURL(template string) string {
+	u, err := url.ParseRequestURI(template)
+	if err != nil {
+		return template
+	}
+	if name := path.Base(u.Path); name != "." && name != "/" {
+		return name
+	}
+	return ""
+}
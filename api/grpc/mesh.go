@@ -0,0 +1,159 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshkit/utils/events"
+)
+
+// OperationNameSeparator separates the name of the mesh from the name of the operation in the operations
+// of a service hosting several meshes, e.g. "istio/istio_install".
+const OperationNameSeparator = "/"
+
+// Mesh is the handler of a mesh hosted by a service along with the handlers of other meshes, see Service.Meshes.
+type Mesh struct {
+	Handler adapter.Handler
+	// EventStreamer is the partition of the event stream the handler publishes its events on. The events are streamed
+	// to StreamEvents subscribers tagged with the name of the mesh, so that they can be selected by mesh.
+	// If nil, the handler is expected to publish on Service.EventStreamer, and its events are not tagged.
+	EventStreamer *events.EventStreamer
+}
+
+// checkHandlers checks that the service has a Handler, or that each of its meshes has one.
+func (s *Service) checkHandlers() error {
+	if len(s.Meshes) == 0 {
+		if s.Handler == nil {
+			return ErrHandlerConfig("the service has neither a Handler nor Meshes")
+		}
+		return nil
+	}
+	for _, name := range s.meshNames() {
+		if m := s.Meshes[name]; m == nil || m.Handler == nil {
+			return ErrHandlerConfig(fmt.Sprintf("mesh %q has no Handler", name))
+		}
+	}
+	return nil
+}
+
+// meshNames returns the names of the meshes hosted, sorted, or nil if the service has a single Handler.
+func (s *Service) meshNames() []string {
+	if len(s.Meshes) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(s.Meshes))
+	for name := range s.Meshes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// handlers returns the handlers of the service by mesh name. The single Handler of a service not hosting several meshes
+// has the empty name.
+func (s *Service) handlers() map[string]adapter.Handler {
+	if len(s.Meshes) == 0 {
+		return map[string]adapter.Handler{"": s.Handler}
+	}
+	handlers := make(map[string]adapter.Handler, len(s.Meshes))
+	for name, m := range s.Meshes {
+		handlers[name] = m.Handler
+	}
+	return handlers
+}
+
+// route returns the name and the handler of the mesh selected by mesh, or by the prefix of the operation name opName,
+// and the name of the operation for the handler. The mesh can be omitted if the service hosts a single mesh.
+// A service with a single Handler accepts the name of its mesh as selector, and returns the empty mesh name.
+func (s *Service) route(mesh, opName string) (string, adapter.Handler, string, error) {
+	if len(s.Meshes) == 0 {
+		if mesh != "" && mesh != s.Handler.GetName() {
+			return "", nil, "", ErrMeshNotFound(mesh)
+		}
+		return "", s.Handler, opName, nil
+	}
+
+	if prefix, name, found := strings.Cut(opName, OperationNameSeparator); found {
+		if _, ok := s.Meshes[prefix]; ok {
+			if mesh != "" && mesh != prefix {
+				return "", nil, "", ErrMeshSelector("operation " + opName + " is not an operation of mesh " + mesh)
+			}
+			mesh, opName = prefix, name
+		}
+	}
+	if mesh == "" {
+		if len(s.Meshes) != 1 {
+			return "", nil, "", ErrMeshSelector("the mesh is required, one of " + strings.Join(s.meshNames(), ", "))
+		}
+		mesh = s.meshNames()[0]
+	}
+	m, ok := s.Meshes[mesh]
+	if !ok || m == nil {
+		return "", nil, "", ErrMeshNotFound(mesh)
+	}
+	return mesh, m.Handler, opName, nil
+}
+
+// qualifiedOperationName returns the name of the operation of the mesh, as listed by SupportedOperations.
+func qualifiedOperationName(mesh, opName string) string {
+	if mesh == "" {
+		return opName
+	}
+	return mesh + OperationNameSeparator + opName
+}
+
+// listOperations returns the operations of all meshes hosted, their names prefixed with the name of their mesh,
+// or the operations of the single Handler.
+func (s *Service) listOperations() (adapter.Operations, error) {
+	if len(s.Meshes) == 0 {
+		return s.Handler.ListOperations()
+	}
+	operations := make(adapter.Operations)
+	for name, m := range s.Meshes {
+		ops, err := m.Handler.ListOperations()
+		if err != nil {
+			return nil, err
+		}
+		for key, op := range ops {
+			operations[qualifiedOperationName(name, key)] = op
+		}
+	}
+	return operations, nil
+}
+
+// eventStreamer returns the partition of the event stream of the mesh, or Service.EventStreamer.
+func (s *Service) eventStreamer(mesh string) *events.EventStreamer {
+	if m, ok := s.Meshes[mesh]; ok && m != nil && m.EventStreamer != nil {
+		return m.EventStreamer
+	}
+	return s.EventStreamer
+}
+
+// hasEvents reports whether events are published, on Service.EventStreamer or on the partitions of the meshes.
+func (s *Service) hasEvents() bool {
+	if s.EventStreamer != nil {
+		return true
+	}
+	for _, m := range s.Meshes {
+		if m != nil && m.EventStreamer != nil {
+			return true
+		}
+	}
+	return false
+}
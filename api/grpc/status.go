@@ -0,0 +1,143 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	"github.com/layer5io/meshkit/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorKey identifies a MeshKit error. MeshKit codes are only unique within the package that defines them,
+// the short description tells errors of different packages sharing a code apart.
+type errorKey struct {
+	code  string
+	short string
+}
+
+func keyOf(err *errors.Error) errorKey {
+	return errorKey{code: err.Code, short: strings.Join(err.ShortDescription, ".")}
+}
+
+// errPlaceholder is used to build the library errors taking a cause, to get their keys.
+var errPlaceholder = stderrors.New("placeholder")
+
+// errorCodes maps the errors of the library to gRPC codes. Other MeshKit errors are mapped by their severity.
+var errorCodes = map[errorKey]codes.Code{
+	keyOf(ErrRequestInvalid):                                             codes.InvalidArgument,
+	keyOf(ErrRequestValidation(nil).(*errors.Error)):                     codes.InvalidArgument,
+	keyOf(ErrUnauthenticated(errPlaceholder).(*errors.Error)):            codes.Unauthenticated,
+	keyOf(ErrSubscriberOverflow):                                         codes.ResourceExhausted,
+	keyOf(ErrOperationNotFound("").(*errors.Error)):                      codes.NotFound,
+	keyOf(ErrUnknownOperationID("").(*errors.Error)):                     codes.NotFound,
+	keyOf(ErrOperationInProgress("").(*errors.Error)):                    codes.AlreadyExists,
+	keyOf(ErrOperationIDReused("").(*errors.Error)):                      codes.AlreadyExists,
+	keyOf(ErrShuttingDown):                                               codes.Unavailable,
+	keyOf(ErrOperationCancelled("").(*errors.Error)):                     codes.Canceled,
+	keyOf(ErrOperationFinished("").(*errors.Error)):                      codes.FailedPrecondition,
+	keyOf(ErrMeshNotFound("").(*errors.Error)):                           codes.NotFound,
+	keyOf(ErrMeshSelector("").(*errors.Error)):                           codes.InvalidArgument,
+	keyOf(ErrEventsForbidden("").(*errors.Error)):                        codes.PermissionDenied,
+	keyOf(adapter.ErrOpInvalid):                                          codes.InvalidArgument,
+	keyOf(adapter.ErrOperationRequest("").(*errors.Error)):               codes.InvalidArgument,
+	keyOf(adapter.ErrValidateKubeconfig(errPlaceholder).(*errors.Error)): codes.InvalidArgument,
+	keyOf(adapter.ErrClientConfig(errPlaceholder).(*errors.Error)):       codes.InvalidArgument,
+	keyOf(adapter.ErrAuthInfosInvalidMsg):                                codes.InvalidArgument,
+	keyOf(adapter.ErrListOperations(errPlaceholder).(*errors.Error)):     codes.Internal,
+}
+
+// severityCodes maps MeshKit severities to gRPC codes, for errors not in errorCodes.
+// No severity maps to codes.Unavailable, which clients retry: it is reserved to the transient errors of the library,
+// e.g. ErrShuttingDown, while handler errors, however severe, are not expected to go away when retried.
+var severityCodes = map[errors.Severity]codes.Code{
+	errors.Emergency: codes.Internal,
+	errors.None:      codes.Unknown,
+	errors.Alert:     codes.Internal,
+	errors.Critical:  codes.Internal,
+	errors.Fatal:     codes.Internal,
+}
+
+// statusError converts err into a gRPC status error. MeshKit errors, possibly wrapped, are mapped to a code
+// by errorCodes or their severity, and carried as ErrorDetails in the status. Status errors are returned unchanged.
+func statusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if stderrors.Is(err, context.Canceled) || stderrors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	var merr *errors.Error
+	if !stderrors.As(err, &merr) {
+		return status.Error(codes.Unknown, err.Error())
+	}
+	msg := err.Error()
+	if msg == "" {
+		msg = strings.Join(merr.ShortDescription, ". ")
+	}
+	return errorStatus(errorCode(merr), msg, merr).Err()
+}
+
+// errorCode returns the gRPC code merr is mapped to.
+func errorCode(merr *errors.Error) codes.Code {
+	if code, ok := errorCodes[keyOf(merr)]; ok {
+		return code
+	}
+	if code, ok := severityCodes[merr.Severity]; ok {
+		return code
+	}
+	return codes.Unknown
+}
+
+// errorStatus returns a status with the given code and message, carrying merr as ErrorDetails.
+func errorStatus(code codes.Code, msg string, merr *errors.Error) *status.Status {
+	st := status.New(code, msg)
+	if withDetails, err := st.WithDetails(errorDetails(merr)); err == nil {
+		st = withDetails
+	}
+	return st
+}
+
+// errorDetails returns the ErrorDetails of a MeshKit error.
+func errorDetails(merr *errors.Error) *meshes.ErrorDetails {
+	return &meshes.ErrorDetails{
+		Code:                 merr.Code,
+		Severity:             meshes.ErrorSeverity(merr.Severity),
+		ShortDescription:     merr.ShortDescription,
+		LongDescription:      merr.LongDescription,
+		ProbableCause:        merr.ProbableCause,
+		SuggestedRemediation: merr.SuggestedRemediation,
+	}
+}
+
+// statusUnaryInterceptor converts the errors returned by unary RPCs with statusError.
+func statusUnaryInterceptor(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	return resp, statusError(err)
+}
+
+// statusStreamInterceptor converts the errors returned by streaming RPCs with statusError.
+func statusStreamInterceptor(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return statusError(handler(srv, ss))
+}
@@ -0,0 +1,167 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+)
+
+const (
+	// ClientAuthRequire requires clients to present a certificate signed by one of the CAs in Service.TLSClientCAFile.
+	ClientAuthRequire = "require"
+	// ClientAuthVerifyIfGiven verifies client certificates against Service.TLSClientCAFile, but does not require them.
+	ClientAuthVerifyIfGiven = "verify-if-given"
+)
+
+// http2Protocol is the ALPN protocol of gRPC.
+const http2Protocol = "h2"
+
+// serverCredentials returns the transport credentials for the server, or nil if TLS is not configured.
+func (s *Service) serverCredentials() (credentials.TransportCredentials, error) {
+	if s.TLSCertFile == "" && s.TLSKeyFile == "" {
+		if s.TLSClientCAFile != "" {
+			return nil, ErrTLSConfig(fmt.Errorf("client CA file %s is set, but no server certificate and key", s.TLSClientCAFile))
+		}
+		return nil, nil
+	}
+
+	clientAuth := tls.NoClientCert
+	if s.TLSClientCAFile != "" {
+		switch s.TLSClientAuth {
+		case "", ClientAuthRequire:
+			clientAuth = tls.RequireAndVerifyClientCert
+		case ClientAuthVerifyIfGiven:
+			clientAuth = tls.VerifyClientCertIfGiven
+		default:
+			return nil, ErrTLSConfig(fmt.Errorf("invalid client auth mode %q, must be one of %q, %q", s.TLSClientAuth, ClientAuthRequire, ClientAuthVerifyIfGiven))
+		}
+	}
+
+	base := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: []string{http2Protocol},
+	}
+	r := &certificateReloader{
+		certFile:   s.TLSCertFile,
+		keyFile:    s.TLSKeyFile,
+		caFile:     s.TLSClientCAFile,
+		clientAuth: clientAuth,
+		nextProtos: base.NextProtos,
+	}
+	if err := r.reload(); err != nil {
+		return nil, ErrTLSConfig(err)
+	}
+
+	base.GetConfigForClient = r.getConfigForClient
+	return credentials.NewTLS(base), nil
+}
+
+// certificateReloader serves the server certificate and the client CA bundle from disk,
+// and reloads them when the files change, e.g. when certificates are rotated, without a restart.
+type certificateReloader struct {
+	certFile   string
+	keyFile    string
+	caFile     string
+	clientAuth tls.ClientAuthType
+	// nextProtos are the protocols negotiated with ALPN, which the configuration returned for each client must list too.
+	nextProtos []string
+
+	mx       sync.Mutex
+	modTimes [3]time.Time
+	config   *tls.Config
+}
+
+// getConfigForClient is called on every handshake. If the files have changed but cannot be loaded,
+// e.g. because the certificate has been rotated but not yet its key, the previous configuration is kept.
+func (r *certificateReloader) getConfigForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	if r.changed() {
+		_ = r.reload()
+	}
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return r.config, nil
+}
+
+func (r *certificateReloader) files() []string {
+	return []string{r.certFile, r.keyFile, r.caFile}
+}
+
+// changed reports whether any of the files has been modified since it was last loaded.
+func (r *certificateReloader) changed() bool {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	for i, f := range r.files() {
+		if f == "" {
+			continue
+		}
+		info, err := os.Stat(f)
+		if err != nil {
+			continue
+		}
+		if !info.ModTime().Equal(r.modTimes[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *certificateReloader) reload() error {
+	var modTimes [3]time.Time
+	for i, f := range r.files() {
+		if f == "" {
+			continue
+		}
+		info, err := os.Stat(f)
+		if err != nil {
+			return err
+		}
+		modTimes[i] = info.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	config := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   r.clientAuth,
+		NextProtos:   r.nextProtos,
+	}
+	if r.caFile != "" {
+		pem, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no valid certificates found in %s", r.caFile)
+		}
+		config.ClientCAs = pool
+	}
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.modTimes = modTimes
+	r.config = config
+	return nil
+}
@@ -0,0 +1,337 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshery-adapter-library/adaptertest"
+	"github.com/layer5io/meshery-adapter-library/api/grpc"
+	"github.com/layer5io/meshery-adapter-library/client"
+	"github.com/layer5io/meshkit/errors"
+)
+
+// testCA issues certificates for the tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+var serial int64
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key := newKey(t)
+	template := &x509.Certificate{
+		SerialNumber:          nextSerial(),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("creating the CA certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parsing the CA certificate: %v", err)
+	}
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns a PEM encoded certificate for the given usage, and its key.
+func (ca *testCA) issue(t *testing.T, name string, usage x509.ExtKeyUsage) (*x509.Certificate, []byte, []byte) {
+	t.Helper()
+	key := newKey(t)
+	template := &x509.Certificate{
+		SerialNumber: nextSerial(),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("creating the certificate of %s: %v", name, err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parsing the certificate of %s: %v", name, err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("encoding the key of %s: %v", name, err)
+	}
+	return cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating a key: %v", err)
+	}
+	return key
+}
+
+func nextSerial() *big.Int {
+	serial++
+	return big.NewInt(serial)
+}
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	if err := os.WriteFile(name, data, 0o600); err != nil {
+		t.Fatalf("writing %s: %v", name, err)
+	}
+	return name
+}
+
+// tlsFiles are the PEM files of a server or client certificate and key.
+type tlsFiles struct {
+	cert, key string
+	x509      *x509.Certificate
+}
+
+func (ca *testCA) writeCert(t *testing.T, dir, name string, usage x509.ExtKeyUsage) tlsFiles {
+	t.Helper()
+	cert, certPEM, keyPEM := ca.issue(t, name, usage)
+	return tlsFiles{
+		cert: writeFile(t, filepath.Join(dir, name+".crt"), certPEM),
+		key:  writeFile(t, filepath.Join(dir, name+".key"), keyPEM),
+		x509: cert,
+	}
+}
+
+// serve runs s on a local TCP port until the test ends, and returns its address.
+func serve(t *testing.T, s *grpc.Service) string {
+	t.Helper()
+	s.Handler = &adaptertest.FakeHandler{Name: "fake"}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- grpc.Serve(ctx, s, listener)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-served; err != nil {
+			t.Errorf("shutting down the service: %v", err)
+		}
+	})
+	return listener.Addr().String()
+}
+
+// call calls MeshName on the adapter at address, with a client using options.
+func call(t *testing.T, address string, options client.Options) error {
+	t.Helper()
+	// Failed handshakes are reported as codes.Unavailable, which the client retries.
+	options.RetryMaxElapsedTime = 200 * time.Millisecond
+	c, err := client.New(address, options)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = c.MeshName(ctx)
+	return err
+}
+
+func TestTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	caFile := writeFile(t, filepath.Join(dir, "ca.crt"), ca.pem)
+	server := ca.writeCert(t, dir, "server", x509.ExtKeyUsageServerAuth)
+	clientCert := ca.writeCert(t, dir, "client", x509.ExtKeyUsageClientAuth)
+
+	otherCA := newTestCA(t)
+	otherClientCert := otherCA.writeCert(t, dir, "other-client", x509.ExtKeyUsageClientAuth)
+
+	withoutCert := client.Options{TLSCAFile: caFile, TLSServerName: "localhost"}
+	withCert := client.Options{TLSCAFile: caFile, TLSServerName: "localhost", TLSCertFile: clientCert.cert, TLSKeyFile: clientCert.key}
+	withUntrustedCert := client.Options{TLSCAFile: caFile, TLSServerName: "localhost", TLSCertFile: otherClientCert.cert, TLSKeyFile: otherClientCert.key}
+	plaintext := client.Options{}
+
+	tests := []struct {
+		name       string
+		service    *grpc.Service
+		options    client.Options
+		wantFailed bool
+	}{
+		{
+			name:    "TLS",
+			service: &grpc.Service{TLSCertFile: server.cert, TLSKeyFile: server.key},
+			options: withoutCert,
+		},
+		{
+			name:       "TLS, plaintext client",
+			service:    &grpc.Service{TLSCertFile: server.cert, TLSKeyFile: server.key},
+			options:    plaintext,
+			wantFailed: true,
+		},
+		{
+			name:    "require client certificate",
+			service: &grpc.Service{TLSCertFile: server.cert, TLSKeyFile: server.key, TLSClientCAFile: caFile},
+			options: withCert,
+		},
+		{
+			name:       "require client certificate, none given",
+			service:    &grpc.Service{TLSCertFile: server.cert, TLSKeyFile: server.key, TLSClientCAFile: caFile, TLSClientAuth: grpc.ClientAuthRequire},
+			options:    withoutCert,
+			wantFailed: true,
+		},
+		{
+			name:       "require client certificate, untrusted one given",
+			service:    &grpc.Service{TLSCertFile: server.cert, TLSKeyFile: server.key, TLSClientCAFile: caFile},
+			options:    withUntrustedCert,
+			wantFailed: true,
+		},
+		{
+			name:    "verify client certificate if given, none given",
+			service: &grpc.Service{TLSCertFile: server.cert, TLSKeyFile: server.key, TLSClientCAFile: caFile, TLSClientAuth: grpc.ClientAuthVerifyIfGiven},
+			options: withoutCert,
+		},
+		{
+			name:    "verify client certificate if given",
+			service: &grpc.Service{TLSCertFile: server.cert, TLSKeyFile: server.key, TLSClientCAFile: caFile, TLSClientAuth: grpc.ClientAuthVerifyIfGiven},
+			options: withCert,
+		},
+		{
+			name:       "verify client certificate if given, untrusted one given",
+			service:    &grpc.Service{TLSCertFile: server.cert, TLSKeyFile: server.key, TLSClientCAFile: caFile, TLSClientAuth: grpc.ClientAuthVerifyIfGiven},
+			options:    withUntrustedCert,
+			wantFailed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address := serve(t, tt.service)
+			err := call(t, address, tt.options)
+			if tt.wantFailed && err == nil {
+				t.Error("call succeeded, want it to fail")
+			}
+			if !tt.wantFailed && err != nil {
+				t.Errorf("call failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestTLSConfigInvalid(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	caFile := writeFile(t, filepath.Join(dir, "ca.crt"), ca.pem)
+	server := ca.writeCert(t, dir, "server", x509.ExtKeyUsageServerAuth)
+
+	tests := []struct {
+		name    string
+		service *grpc.Service
+	}{
+		{
+			name:    "client CA without certificate and key",
+			service: &grpc.Service{TLSClientCAFile: caFile},
+		},
+		{
+			name:    "invalid client auth mode",
+			service: &grpc.Service{TLSCertFile: server.cert, TLSKeyFile: server.key, TLSClientCAFile: caFile, TLSClientAuth: "optional"},
+		},
+		{
+			name:    "missing key",
+			service: &grpc.Service{TLSCertFile: server.cert, TLSKeyFile: filepath.Join(dir, "missing.key")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("listening: %v", err)
+			}
+			tt.service.Handler = &adaptertest.FakeHandler{Name: "fake"}
+			err = grpc.Serve(context.Background(), tt.service, listener)
+			if err == nil {
+				t.Fatal("Serve succeeded, want it to fail")
+			}
+			if _, ok := errors.Is(err); !ok || errors.GetCode(err) != grpc.ErrTLSConfigCode {
+				t.Errorf("Serve error = %v, want a MeshKit error with code %s", err, grpc.ErrTLSConfigCode)
+			}
+		})
+	}
+}
+
+func TestTLSCertificateRotation(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	server := ca.writeCert(t, dir, "server", x509.ExtKeyUsageServerAuth)
+	address := serve(t, &grpc.Service{TLSCertFile: server.cert, TLSKeyFile: server.key})
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	servedCertificate := func() *x509.Certificate {
+		t.Helper()
+		conn, err := tls.Dial("tcp", address, &tls.Config{RootCAs: pool, ServerName: "localhost", NextProtos: []string{"h2"}})
+		if err != nil {
+			t.Fatalf("handshake: %v", err)
+		}
+		defer conn.Close()
+		if got := conn.ConnectionState().NegotiatedProtocol; got != "h2" {
+			t.Errorf("negotiated protocol %q, want h2", got)
+		}
+		return conn.ConnectionState().PeerCertificates[0]
+	}
+
+	if got := servedCertificate(); got.SerialNumber.Cmp(server.x509.SerialNumber) != 0 {
+		t.Fatalf("served certificate %v, want %v", got.SerialNumber, server.x509.SerialNumber)
+	}
+
+	rotated, certPEM, keyPEM := ca.issue(t, "server", x509.ExtKeyUsageServerAuth)
+	writeFile(t, server.cert, certPEM)
+	writeFile(t, server.key, keyPEM)
+	// The files are reloaded when their modification time changes, which may be too coarse to notice the rewrite.
+	later := time.Now().Add(time.Minute)
+	for _, f := range []string{server.cert, server.key} {
+		if err := os.Chtimes(f, later, later); err != nil {
+			t.Fatalf("touching %s: %v", f, err)
+		}
+	}
+
+	if got := servedCertificate(); got.SerialNumber.Cmp(rotated.SerialNumber) != 0 {
+		t.Errorf("served certificate %v after rotation, want %v", got.SerialNumber, rotated.SerialNumber)
+	}
+}
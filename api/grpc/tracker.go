@@ -0,0 +1,299 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxTrackedOperations is the number of operations tracked. Once it is reached, the oldest finished operations are evicted.
+// Finished operations are also forgotten once their TTL has expired, see Service.OperationTTL.
+const maxTrackedOperations = 1024
+
+// operationTracker records the state of the operations applied through ApplyOperation and ProcessOAM, see GetOperationStatus,
+// and cancels them on request, see CancelOperation. It deduplicates the operations applied again with the same ID.
+// It also remembers the user that applied each operation, so that events can be filtered by user.
+type operationTracker struct {
+	mx    sync.Mutex
+	ops   map[string]*trackedOperation
+	order []string // operation IDs, in the order they were added
+}
+
+type trackedOperation struct {
+	name      string
+	owner     string
+	hash      string // identifies the request, empty if the operation is not deduplicated
+	ttl       time.Duration
+	state     meshes.OperationState
+	startedAt time.Time
+	endedAt   time.Time
+	lastEvent *meshes.EventsResponse
+	err       string
+	result    error         // error the operation finished with, if any
+	done      chan struct{} // closed when the operation has finished
+	cancel    context.CancelFunc
+	cancelled bool
+}
+
+func (t *trackedOperation) finished() bool {
+	switch t.state {
+	case meshes.OperationState_SUCCEEDED, meshes.OperationState_FAILED, meshes.OperationState_CANCELLED:
+		return true
+	}
+	return false
+}
+
+// expired reports whether the operation has finished longer than its TTL ago.
+func (t *trackedOperation) expired(now time.Time) bool {
+	return t.finished() && t.ttl > 0 && now.Sub(t.endedAt) > t.ttl
+}
+
+// add registers the operation with the given ID as queued, cancel being the function cancelling its context,
+// and ttl the time it is remembered once it has finished. hash identifies the request, see requestHash.
+//
+// add returns the operation, whose done channel is closed once it has finished, see result. If an operation with the same ID
+// is tracked, the request is a duplicate: add reports it, and returns that operation, provided the hashes match. It fails with ErrOperationIDReused otherwise. Operations without hash are not deduplicated: adding one fails
+// with ErrOperationInProgress while an operation with the same ID has not finished, and replaces it afterwards.
+func (t *operationTracker) add(operationID, name, owner, hash string, ttl time.Duration, cancel context.CancelFunc) (*trackedOperation, bool, error) {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	if t.ops == nil {
+		t.ops = make(map[string]*trackedOperation)
+	}
+	if op, ok := t.lookup(operationID); ok {
+		switch {
+		case hash != "" && op.hash == hash:
+			return op, true, nil
+		case hash != "":
+			return nil, false, ErrOperationIDReused(operationID)
+		case !op.finished():
+			return nil, false, ErrOperationInProgress(operationID)
+		}
+		t.remove(operationID)
+	}
+	op := &trackedOperation{
+		name:   name,
+		owner:  owner,
+		hash:   hash,
+		ttl:    ttl,
+		state:  meshes.OperationState_QUEUED,
+		done:   make(chan struct{}),
+		cancel: cancel,
+	}
+	t.ops[operationID] = op
+	t.order = append(t.order, operationID)
+	t.evict()
+	return op, false, nil
+}
+
+// result returns the error the operation op, returned by add, finished with, even if it is not tracked anymore.
+func (t *operationTracker) result(op *trackedOperation) error {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	return op.result
+}
+
+// start marks the operation as running.
+func (t *operationTracker) start(operationID string) {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	if op, ok := t.ops[operationID]; ok {
+		op.state = meshes.OperationState_RUNNING
+		op.startedAt = time.Now()
+	}
+}
+
+// finish marks the operation as succeeded, or as failed if err is not nil, or as cancelled if it failed
+// after a cancellation was requested. It returns the final state of the operation.
+func (t *operationTracker) finish(operationID string, err error) meshes.OperationState {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	op, ok := t.ops[operationID]
+	if !ok || op.finished() {
+		if err != nil {
+			return meshes.OperationState_FAILED
+		}
+		return meshes.OperationState_SUCCEEDED
+	}
+	switch {
+	case err == nil:
+		op.state = meshes.OperationState_SUCCEEDED
+	case op.cancelled:
+		op.state = meshes.OperationState_CANCELLED
+		op.result = ErrOperationCancelled(operationID)
+	default:
+		op.state = meshes.OperationState_FAILED
+		op.result = err
+	}
+	if op.result != nil {
+		op.err = op.result.Error()
+	}
+	op.endedAt = time.Now()
+	op.cancel()
+	close(op.done)
+	return op.state
+}
+
+// discard fails the queued operation with err, and forgets it, so that a request with the same ID, e.g. retried after
+// the adapter has restarted, applies it rather than getting err back. It returns false, leaving the operation to finish,
+// if the operation is not queued, or has been cancelled, which is recorded.
+func (t *operationTracker) discard(operationID string, err error) bool {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	op, ok := t.ops[operationID]
+	if !ok || op.cancelled || op.state != meshes.OperationState_QUEUED {
+		return false
+	}
+	op.state = meshes.OperationState_FAILED
+	op.result = err
+	op.err = err.Error()
+	op.endedAt = time.Now()
+	op.cancel()
+	close(op.done)
+	t.remove(operationID)
+	return true
+}
+
+// cancelOperation cancels the context of the operation. It returns false if the operation is not tracked,
+// and whether the operation was still queued or running.
+func (t *operationTracker) cancelOperation(operationID string) (bool, bool) {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	op, ok := t.lookup(operationID)
+	if !ok {
+		return false, false
+	}
+	if op.finished() {
+		return true, false
+	}
+	op.cancelled = true
+	op.cancel()
+	return true, true
+}
+
+// cancelAll cancels the contexts of the operations that are queued or running, which are recorded as cancelled
+// once they have returned, e.g. when the server shuts down.
+func (t *operationTracker) cancelAll() {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	for _, op := range t.ops {
+		if !op.finished() {
+			op.cancelled = true
+			op.cancel()
+		}
+	}
+}
+
+// observe records e as the last event of its operation, if the operation is tracked.
+func (t *operationTracker) observe(e *meshes.EventsResponse) {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	if op, ok := t.lookup(e.OperationId); ok {
+		op.lastEvent = e
+	}
+}
+
+// owner returns the user that applied the operation, or "" if it is unknown.
+func (t *operationTracker) owner(operationID string) string {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	if op, ok := t.lookup(operationID); ok {
+		return op.owner
+	}
+	return ""
+}
+
+// status returns the current status of the operation, and false if it is not tracked.
+func (t *operationTracker) status(operationID string) (*meshes.OperationStatusResponse, bool) {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	op, ok := t.lookup(operationID)
+	if !ok {
+		return nil, false
+	}
+	resp := &meshes.OperationStatusResponse{
+		OperationId:   operationID,
+		OperationName: op.name,
+		State:         op.state,
+		Error:         op.err,
+	}
+	if !op.startedAt.IsZero() {
+		resp.StartedAt = timestamppb.New(op.startedAt)
+	}
+	if !op.endedAt.IsZero() {
+		resp.EndedAt = timestamppb.New(op.endedAt)
+	}
+	if op.lastEvent != nil {
+		resp.LastEvent = proto.Clone(op.lastEvent).(*meshes.EventsResponse)
+	}
+	return resp, true
+}
+
+// lookup returns the operation with the given ID, unless it has expired, in which case it is removed.
+func (t *operationTracker) lookup(operationID string) (*trackedOperation, bool) {
+	op, ok := t.ops[operationID]
+	if !ok {
+		return nil, false
+	}
+	if op.expired(time.Now()) {
+		t.remove(operationID)
+		return nil, false
+	}
+	return op, true
+}
+
+// evict removes the expired operations, and the oldest finished operations while more than maxTrackedOperations
+// are tracked. Operations that have not finished are never evicted.
+func (t *operationTracker) evict() {
+	now := time.Now()
+	for i := 0; i < len(t.order); {
+		if id := t.order[i]; t.ops[id].expired(now) || len(t.ops) > maxTrackedOperations && t.ops[id].finished() {
+			delete(t.ops, id)
+			t.order = append(t.order[:i], t.order[i+1:]...)
+			continue
+		}
+		i++
+	}
+}
+
+func (t *operationTracker) remove(operationID string) {
+	delete(t.ops, operationID)
+	for i, id := range t.order {
+		if id == operationID {
+			t.order = append(t.order[:i], t.order[i+1:]...)
+			return
+		}
+	}
+}
+
+// requestHash returns the hash identifying an ApplyOperation request, as applied by username, regardless of its
+// operation ID and whether it is asynchronous. A request applied again with the same ID must have the same hash.
+func requestHash(req *meshes.ApplyRuleRequest, username string) string {
+	r := proto.Clone(req).(*meshes.ApplyRuleRequest)
+	r.OperationId = ""
+	r.Async = false
+	r.Username = username
+	b, _ := proto.MarshalOptions{Deterministic: true}.Marshal(r)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
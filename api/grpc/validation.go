@@ -0,0 +1,181 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	"github.com/layer5io/meshkit/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// FieldViolation describes why a field of a request is invalid. Field is the name of the field in the proto
+// definition of the request, e.g. "namespace".
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
+// Validator checks an ApplyOperation request before it reaches the handler, and returns the violations found, if any.
+// op is the descriptor of the operation requested, as listed by the handler.
+type Validator func(ctx context.Context, req *meshes.ApplyRuleRequest, op *adapter.Operation) []FieldViolation
+
+// DefaultValidators are the validators used if Service.Validators is not set.
+var DefaultValidators = []Validator{
+	ValidateVersion,
+	ValidateNamespace,
+	ValidateKubeConfigs,
+	ValidateCustomBody,
+}
+
+// ValidateVersion checks that the version requested, if any, is one of the versions of the operation.
+// Operations without versions accept any version.
+func ValidateVersion(_ context.Context, req *meshes.ApplyRuleRequest, op *adapter.Operation) []FieldViolation {
+	if req.GetVersion() == "" || len(op.Versions) == 0 {
+		return nil
+	}
+	versions := make([]string, 0, len(op.Versions))
+	for _, v := range op.Versions {
+		versions = append(versions, v.String())
+	}
+	versions = adapter.SortVersions(versions)
+	if len(versions) == 0 {
+		return nil
+	}
+	for _, v := range versions {
+		if sameVersion(v, req.GetVersion()) {
+			return nil
+		}
+	}
+	return []FieldViolation{{
+		Field:       "version",
+		Description: fmt.Sprintf("version %q is not supported by operation %q, use one of %s", req.GetVersion(), req.GetOpName(), strings.Join(versions, ", ")),
+	}}
+}
+
+// sameVersion reports whether a and b are the same version, e.g. "v1.2.0" and "1.2.0".
+func sameVersion(a, b string) bool {
+	if a == b {
+		return true
+	}
+	va, err := semver.NewVersion(a)
+	if err != nil {
+		return false
+	}
+	vb, err := semver.NewVersion(b)
+	if err != nil {
+		return false
+	}
+	return va.Equal(vb)
+}
+
+// ValidateNamespace checks that the namespace, if set, is a valid Kubernetes namespace name.
+func ValidateNamespace(_ context.Context, req *meshes.ApplyRuleRequest, _ *adapter.Operation) []FieldViolation {
+	if req.GetNamespace() == "" {
+		return nil
+	}
+	violations := make([]FieldViolation, 0)
+	for _, msg := range validation.IsDNS1123Label(req.GetNamespace()) {
+		violations = append(violations, FieldViolation{
+			Field:       "namespace",
+			Description: fmt.Sprintf("namespace %q is invalid: %s", req.GetNamespace(), msg),
+		})
+	}
+	return violations
+}
+
+// ValidateKubeConfigs checks that at least one kubeconfig is given, and that none is empty.
+func ValidateKubeConfigs(_ context.Context, req *meshes.ApplyRuleRequest, _ *adapter.Operation) []FieldViolation {
+	if len(req.GetKubeConfigs()) == 0 {
+		return []FieldViolation{{Field: "kube_configs", Description: "at least one kubeconfig is required"}}
+	}
+	violations := make([]FieldViolation, 0)
+	for i, kubeconfig := range req.GetKubeConfigs() {
+		if strings.TrimSpace(kubeconfig) == "" {
+			violations = append(violations, FieldViolation{
+				Field:       fmt.Sprintf("kube_configs[%d]", i),
+				Description: "kubeconfig is empty",
+			})
+		}
+	}
+	return violations
+}
+
+// ValidateCustomBody checks that a custom body is only given to custom operations.
+func ValidateCustomBody(_ context.Context, req *meshes.ApplyRuleRequest, op *adapter.Operation) []FieldViolation {
+	if req.GetCustomBody() == "" || op.Type == int32(meshes.OpCategory_CUSTOM) {
+		return nil
+	}
+	return []FieldViolation{{
+		Field:       "custom_body",
+		Description: fmt.Sprintf("operation %q is not a custom operation and does not take a custom body", req.GetOpName()),
+	}}
+}
+
+// validate checks the request against the operations of handler and the validators of the service.
+// It returns an InvalidArgument status error, with the violations as BadRequest details in addition to the ErrorDetails,
+// if the request is invalid.
+func (s *Service) validate(ctx context.Context, req *meshes.ApplyRuleRequest, handler adapter.Handler) error {
+	ops, err := handler.ListOperations()
+	if err != nil {
+		return err
+	}
+	op, ok := ops[req.GetOpName()]
+	if !ok || op == nil {
+		return invalidArgument([]FieldViolation{{
+			Field:       "op_name",
+			Description: ErrOperationNotFound(req.GetOpName()).Error(),
+		}})
+	}
+
+	validators := s.Validators
+	if validators == nil {
+		validators = DefaultValidators
+	}
+	violations := make([]FieldViolation, 0)
+	for _, validate := range validators {
+		violations = append(violations, validate(ctx, req, op)...)
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	return invalidArgument(violations)
+}
+
+// invalidArgument returns an InvalidArgument status error, with violations as BadRequest details.
+func invalidArgument(violations []FieldViolation) error {
+	details := &errdetails.BadRequest{}
+	descriptions := make([]string, 0, len(violations))
+	for _, v := range violations {
+		details.FieldViolations = append(details.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Description,
+		})
+		descriptions = append(descriptions, fmt.Sprintf("%s: %s", v.Field, v.Description))
+	}
+	err := ErrRequestValidation(descriptions)
+	st := errorStatus(codes.InvalidArgument, err.Error(), err.(*errors.Error))
+	if withDetails, err := st.WithDetails(details); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
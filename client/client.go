@@ -0,0 +1,324 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package client implements a high-level client of the MeshService served by adapters (see package api/grpc).
+//
+// An AdapterClient is created with New, and manages the connection to the adapter. Calls failing with codes.Unavailable,
+// e.g. while the adapter restarts, are retried with exponential backoff. Events are read with an EventIterator,
+// which reconnects automatically and resumes the stream where it left off.
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"time"
+
+	backoff "github.com/cenkalti/backoff/v4"
+	"github.com/google/uuid"
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultRetryMaxElapsedTime is used if Options.RetryMaxElapsedTime is not set.
+const DefaultRetryMaxElapsedTime = 30 * time.Second
+
+// Options holds the parameters of the connection to an adapter.
+type Options struct {
+	// TLSCAFile is the path to the PEM encoded CA bundle used to verify the adapter's certificate.
+	// If set, the client connects with TLS.
+	TLSCAFile string
+	// TLSCertFile and TLSKeyFile are the paths to the PEM encoded client certificate and key, for mutual TLS.
+	TLSCertFile string
+	TLSKeyFile  string
+	// TLSServerName overrides the name used to verify the adapter's certificate, which defaults to the host of the address.
+	TLSServerName string
+	// TLSConfig, if set, is used instead of the TLS files above.
+	TLSConfig *tls.Config
+
+	// BearerToken is sent with every call, for adapters authenticating their clients with a BearerTokenAuthenticator.
+	BearerToken string
+
+	// RetryInitialInterval and RetryMaxInterval bound the backoff between retries, and default to the values of backoff.ExponentialBackOff.
+	RetryInitialInterval time.Duration
+	RetryMaxInterval     time.Duration
+	// RetryMaxElapsedTime is the time after which a call is not retried anymore. Defaults to DefaultRetryMaxElapsedTime.
+	RetryMaxElapsedTime time.Duration
+
+	// DialOptions are appended to the options used to dial the adapter, e.g. to use a custom dialer.
+	DialOptions []grpc.DialOption
+}
+
+// AdapterClient is a client of an adapter's MeshService.
+type AdapterClient struct {
+	conn    *grpc.ClientConn
+	client  meshes.MeshServiceClient
+	options Options
+}
+
+// New creates a client of the adapter listening on address. The connection is set up lazily, on first use.
+func New(address string, options Options) (*AdapterClient, error) {
+	creds, err := options.transportCredentials()
+	if err != nil {
+		return nil, err
+	}
+	dialOptions := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if options.BearerToken != "" {
+		dialOptions = append(dialOptions, grpc.WithPerRPCCredentials(bearerToken{
+			token:  options.BearerToken,
+			secure: creds.Info().SecurityProtocol == "tls",
+		}))
+	}
+	dialOptions = append(dialOptions, options.DialOptions...)
+
+	conn, err := grpc.Dial(address, dialOptions...)
+	if err != nil {
+		return nil, ErrDial(err)
+	}
+	return &AdapterClient{
+		conn:    conn,
+		client:  meshes.NewMeshServiceClient(conn),
+		options: options,
+	}, nil
+}
+
+// Close closes the connection to the adapter.
+func (c *AdapterClient) Close() error {
+	return c.conn.Close()
+}
+
+// MeshServiceClient returns the underlying client, for calls that are not covered by AdapterClient.
+func (c *AdapterClient) MeshServiceClient() meshes.MeshServiceClient {
+	return c.client
+}
+
+// MeshName returns the name of the adapter.
+func (c *AdapterClient) MeshName(ctx context.Context) (string, error) {
+	var resp *meshes.MeshNameResponse
+	err := c.retry(ctx, func(ctx context.Context) (err error) {
+		resp, err = c.client.MeshName(ctx, &meshes.MeshNameRequest{})
+		return err
+	})
+	return resp.GetName(), err
+}
+
+// SupportedOperations returns the operations supported by the adapter, keyed by operation name.
+func (c *AdapterClient) SupportedOperations(ctx context.Context) (adapter.Operations, error) {
+	var resp *meshes.SupportedOperationsResponse
+	err := c.retry(ctx, func(ctx context.Context) (err error) {
+		resp, err = c.client.SupportedOperations(ctx, &meshes.SupportedOperationsRequest{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ops := make(adapter.Operations, len(resp.GetOps()))
+	for _, o := range resp.GetOps() {
+		op := &adapter.Operation{
+			Type:                 int32(o.GetCategory()),
+			Description:          o.GetValue(),
+			AdditionalProperties: o.GetAdditionalProperties(),
+		}
+		for _, v := range o.GetVersions() {
+			op.Versions = append(op.Versions, adapter.Version(v))
+		}
+		for _, t := range o.GetTemplates() {
+			op.Templates = append(op.Templates, adapter.Template(t))
+		}
+		for _, s := range o.GetServices() {
+			op.Services = append(op.Services, adapter.Service(s))
+		}
+		ops[o.GetKey()] = op
+	}
+	return ops, nil
+}
+
+// MeshVersions returns the mesh versions the adapter can install, or the versions of the operation named operationName if it is not empty.
+func (c *AdapterClient) MeshVersions(ctx context.Context, operationName string) ([]string, error) {
+	var resp *meshes.MeshVersionsResponse
+	err := c.retry(ctx, func(ctx context.Context) (err error) {
+		resp, err = c.client.MeshVersions(ctx, &meshes.MeshVersionsRequest{OperationName: operationName})
+		return err
+	})
+	return resp.GetVersion(), err
+}
+
+// ComponentInfo returns the identity, build information and capabilities of the adapter.
+func (c *AdapterClient) ComponentInfo(ctx context.Context) (*meshes.ComponentInfoResponse, error) {
+	var resp *meshes.ComponentInfoResponse
+	err := c.retry(ctx, func(ctx context.Context) (err error) {
+		resp, err = c.client.ComponentInfo(ctx, &meshes.ComponentInfoRequest{})
+		return err
+	})
+	return resp, err
+}
+
+// Apply applies the operation, and returns its ID once the adapter has completed it.
+// An operation ID is generated if op.OperationID is empty, so that a retried call cannot apply the operation twice.
+func (c *AdapterClient) Apply(ctx context.Context, op adapter.OperationRequest) (string, error) {
+	return c.apply(ctx, op, false)
+}
+
+// ApplyAsync applies the operation, and returns its ID as soon as the adapter has accepted it.
+// The progress of the operation can be followed with OperationStatus, or with the events of the operation.
+func (c *AdapterClient) ApplyAsync(ctx context.Context, op adapter.OperationRequest) (string, error) {
+	return c.apply(ctx, op, true)
+}
+
+func (c *AdapterClient) apply(ctx context.Context, op adapter.OperationRequest, async bool) (string, error) {
+	if op.OperationID == "" {
+		op.OperationID = uuid.NewString()
+	}
+	req := &meshes.ApplyRuleRequest{
+		OpName:      op.OperationName,
+		Namespace:   op.Namespace,
+		Username:    op.Username,
+		CustomBody:  op.CustomBody,
+		DeleteOp:    op.IsDeleteOperation,
+		OperationId: op.OperationID,
+		KubeConfigs: op.K8sConfigs,
+		Version:     op.Version,
+		Async:       async,
+		Mesh:        op.Mesh,
+		Values:      op.Values,
+	}
+	err := c.retry(ctx, func(ctx context.Context) error {
+		_, err := c.client.ApplyOperation(ctx, req)
+		return err
+	})
+	return op.OperationID, err
+}
+
+// OperationStatus returns the current status of the operation with the given ID.
+func (c *AdapterClient) OperationStatus(ctx context.Context, operationID string) (*meshes.OperationStatusResponse, error) {
+	var resp *meshes.OperationStatusResponse
+	err := c.retry(ctx, func(ctx context.Context) (err error) {
+		resp, err = c.client.GetOperationStatus(ctx, &meshes.OperationStatusRequest{OperationId: operationID})
+		return err
+	})
+	return resp, err
+}
+
+// Cancel cancels the operation with the given ID.
+func (c *AdapterClient) Cancel(ctx context.Context, operationID string) error {
+	return c.retry(ctx, func(ctx context.Context) error {
+		_, err := c.client.CancelOperation(ctx, &meshes.CancelOperationRequest{OperationId: operationID})
+		return err
+	})
+}
+
+// ProcessOAM processes the OAM components and configuration of the request, and returns the adapter's message.
+func (c *AdapterClient) ProcessOAM(ctx context.Context, oam adapter.OAMRequest) (string, error) {
+	req := &meshes.ProcessOAMRequest{
+		Username:    oam.Username,
+		DeleteOp:    oam.DeleteOp,
+		OamComps:    oam.OamComps,
+		OamConfig:   oam.OamConfig,
+		KubeConfigs: oam.K8sConfigs,
+		OperationId: oam.OperationID,
+		Mesh:        oam.Mesh,
+	}
+	var resp *meshes.ProcessOAMResponse
+	err := c.retry(ctx, func(ctx context.Context) (err error) {
+		resp, err = c.client.ProcessOAM(ctx, req)
+		return err
+	})
+	return resp.GetMessage(), err
+}
+
+// retry calls call until it succeeds, or fails with an error other than codes.Unavailable, or the retries are exhausted.
+// Errors carrying MeshKit error details are returned as StatusError.
+func (c *AdapterClient) retry(ctx context.Context, call func(context.Context) error) error {
+	return decodeError(backoff.Retry(func() error {
+		err := call(ctx)
+		if err != nil && status.Code(err) != codes.Unavailable {
+			return backoff.Permanent(err)
+		}
+		return err
+	}, backoff.WithContext(c.newBackOff(), ctx)))
+}
+
+func (c *AdapterClient) newBackOff() *backoff.ExponentialBackOff {
+	b := backoff.NewExponentialBackOff()
+	if c.options.RetryInitialInterval > 0 {
+		b.InitialInterval = c.options.RetryInitialInterval
+	}
+	if c.options.RetryMaxInterval > 0 {
+		b.MaxInterval = c.options.RetryMaxInterval
+	}
+	b.MaxElapsedTime = DefaultRetryMaxElapsedTime
+	if c.options.RetryMaxElapsedTime > 0 {
+		b.MaxElapsedTime = c.options.RetryMaxElapsedTime
+	}
+	b.Reset()
+	return b
+}
+
+// transportCredentials returns the credentials matching the TLS options, or insecure credentials if TLS is not configured.
+func (o *Options) transportCredentials() (credentials.TransportCredentials, error) {
+	if o.TLSConfig != nil {
+		return credentials.NewTLS(o.TLSConfig), nil
+	}
+	if o.TLSCAFile == "" && o.TLSCertFile == "" && o.TLSKeyFile == "" {
+		return insecure.NewCredentials(), nil
+	}
+
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: o.TLSServerName,
+	}
+	if o.TLSCAFile != "" {
+		pem, err := os.ReadFile(o.TLSCAFile)
+		if err != nil {
+			return nil, ErrTLSConfig(err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, ErrTLSConfig(fmt.Errorf("no valid certificates found in %s", o.TLSCAFile))
+		}
+		config.RootCAs = pool
+	}
+	if o.TLSCertFile != "" || o.TLSKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.TLSCertFile, o.TLSKeyFile)
+		if err != nil {
+			return nil, ErrTLSConfig(err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return credentials.NewTLS(config), nil
+}
+
+// bearerToken sends the token in the authorization header of every call.
+type bearerToken struct {
+	token  string
+	secure bool
+}
+
+func (t bearerToken) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + t.token}, nil
+}
+
+// RequireTransportSecurity only allows sending the token in clear text if TLS is not configured at all,
+// e.g. for adapters running as a sidecar.
+func (t bearerToken) RequireTransportSecurity() bool {
+	return t.secure
+}
@@ -0,0 +1,284 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	stderrors "errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshery-adapter-library/adaptertest"
+	"github.com/layer5io/meshery-adapter-library/api/grpc"
+	"github.com/layer5io/meshery-adapter-library/client"
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	"github.com/layer5io/meshkit/utils/events"
+	ggrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const testTimeout = 10 * time.Second
+
+func newFakeHandler(es *events.EventStreamer) *adaptertest.FakeHandler {
+	return &adaptertest.FakeHandler{
+		Name:          "fake",
+		EventStreamer: es,
+		Operations: adapter.Operations{
+			"fake_install": {Type: int32(meshes.OpCategory_INSTALL), Description: "Fake mesh"},
+		},
+	}
+}
+
+func newService() *grpc.Service {
+	es := events.NewEventStreamer()
+	return &grpc.Service{Handler: newFakeHandler(es), EventStreamer: es}
+}
+
+// unavailableInterceptor fails the first n calls with codes.Unavailable, and counts the calls.
+func unavailableInterceptor(n int32, calls *atomic.Int32) ggrpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *ggrpc.UnaryServerInfo, handler ggrpc.UnaryHandler) (interface{}, error) {
+		if calls.Add(1) <= n {
+			return nil, status.Error(codes.Unavailable, "adapter starting")
+		}
+		return handler(ctx, req)
+	}
+}
+
+func TestRetryOnUnavailable(t *testing.T) {
+	var calls atomic.Int32
+	s := newService()
+	s.UnaryInterceptors = []ggrpc.UnaryServerInterceptor{unavailableInterceptor(2, &calls)}
+	srv := adaptertest.NewServer(t, s)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	name, err := srv.Client.MeshName(ctx)
+	if err != nil {
+		t.Fatalf("MeshName: %v", err)
+	}
+	if name != "fake" {
+		t.Errorf("MeshName = %q, want %q", name, "fake")
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("MeshName called %d times, want 3", got)
+	}
+}
+
+func TestNoRetryOnOtherErrors(t *testing.T) {
+	var calls atomic.Int32
+	s := newService()
+	s.UnaryInterceptors = []ggrpc.UnaryServerInterceptor{
+		func(ctx context.Context, req interface{}, _ *ggrpc.UnaryServerInfo, _ ggrpc.UnaryHandler) (interface{}, error) {
+			calls.Add(1)
+			return nil, status.Error(codes.PermissionDenied, "denied")
+		},
+	}
+	srv := adaptertest.NewServer(t, s)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	if _, err := srv.Client.MeshName(ctx); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("MeshName error = %v, want code PermissionDenied", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("MeshName called %d times, want 1", got)
+	}
+}
+
+func TestStatusErrorDecoding(t *testing.T) {
+	srv := adaptertest.NewServer(t, newService())
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	_, err := srv.Client.OperationStatus(ctx, "unknown")
+	if err == nil {
+		t.Fatal("OperationStatus of an unknown operation succeeded")
+	}
+
+	var statusErr *client.StatusError
+	if !stderrors.As(err, &statusErr) {
+		t.Fatalf("error %v (%T) is not a StatusError", err, err)
+	}
+	if got := status.Code(err); got != codes.NotFound {
+		t.Errorf("status code = %v, want %v", got, codes.NotFound)
+	}
+	merr, ok := client.MeshKitError(err)
+	if !ok {
+		t.Fatalf("error %v does not carry a MeshKit error", err)
+	}
+	if merr.Code != grpc.ErrUnknownOperationIDCode {
+		t.Errorf("MeshKit error code = %q, want %q", merr.Code, grpc.ErrUnknownOperationIDCode)
+	}
+	if len(merr.SuggestedRemediation) == 0 {
+		t.Error("MeshKit error has no suggested remediation")
+	}
+}
+
+func TestEventsPublishedBeforeNext(t *testing.T) {
+	srv := adaptertest.NewServer(t, newService())
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	operationID := "3d3f0fbf-1c44-4b5b-9a5e-2a2c7e7b4f0d"
+	it := srv.Client.Events(ctx, client.EventFilter{OperationIDs: []string{operationID}})
+	defer it.Close()
+
+	_, err := srv.Client.ApplyAsync(ctx, adapter.OperationRequest{
+		OperationName: "fake_install",
+		OperationID:   operationID,
+		K8sConfigs:    []string{"kubeconfig"},
+	})
+	if err != nil {
+		t.Fatalf("ApplyAsync: %v", err)
+	}
+	// The event is published before the iterator is read from.
+	if _, err := srv.Events.WaitForEvent(ctx, adaptertest.WithOperationID(operationID)); err != nil {
+		t.Fatalf("waiting for the event: %v", err)
+	}
+
+	if !it.Next() {
+		t.Fatalf("Next: %v", it.Err())
+	}
+	if got := it.Event().GetOperationId(); got != operationID {
+		t.Errorf("event of operation %q, want %q", got, operationID)
+	}
+}
+
+func TestEventsReplayTruncated(t *testing.T) {
+	s := newService()
+	s.EventReplaySize = 1
+	srv := adaptertest.NewServer(t, s)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	live := srv.Client.Events(ctx, client.EventFilter{})
+	defer live.Close()
+	for i := 0; i < 3; i++ {
+		s.EventStreamer.Publish(&meshes.EventsResponse{Summary: "event"})
+	}
+	for i := 0; i < 3; i++ {
+		if !live.Next() {
+			t.Fatalf("Next: %v", live.Err())
+		}
+	}
+
+	it := srv.Client.Events(ctx, client.EventFilter{FromSequence: 1})
+	defer it.Close()
+	if !it.ReplayTruncated() {
+		t.Error("ReplayTruncated = false for a replay from an evicted event")
+	}
+	if !it.Next() {
+		t.Fatalf("Next: %v", it.Err())
+	}
+	if got := it.Event().GetSequence(); got != 3 {
+		t.Errorf("first event replayed has sequence %d, want 3", got)
+	}
+}
+
+// restartableServer serves a Service on in-memory listeners, and can be stopped and started again,
+// as an adapter restarting.
+type restartableServer struct {
+	t       *testing.T
+	service *grpc.Service
+
+	mx       sync.Mutex
+	listener *bufconn.Listener
+	stop     context.CancelFunc
+	served   chan error
+}
+
+func (r *restartableServer) start() {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.listener = bufconn.Listen(1 << 20)
+	ctx, cancel := context.WithCancel(context.Background())
+	r.stop = cancel
+	r.served = make(chan error, 1)
+	go func(listener net.Listener, served chan<- error) {
+		served <- grpc.Serve(ctx, r.service, listener)
+	}(r.listener, r.served)
+}
+
+func (r *restartableServer) shutdown() {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.stop()
+	if err := <-r.served; err != nil {
+		r.t.Errorf("shutting down the service: %v", err)
+	}
+}
+
+func (r *restartableServer) dial(ctx context.Context, _ string) (net.Conn, error) {
+	r.mx.Lock()
+	listener := r.listener
+	r.mx.Unlock()
+	return listener.DialContext(ctx)
+}
+
+func TestEventsResumeAfterRestart(t *testing.T) {
+	s := newService()
+	r := &restartableServer{t: t, service: s}
+	r.start()
+
+	c, err := client.New("passthrough:///bufconn", client.Options{
+		RetryInitialInterval: 10 * time.Millisecond,
+		RetryMaxInterval:     50 * time.Millisecond,
+		DialOptions:          []ggrpc.DialOption{ggrpc.WithContextDialer(r.dial)},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	it := c.Events(ctx, client.EventFilter{})
+	defer it.Close()
+
+	s.EventStreamer.Publish(&meshes.EventsResponse{Summary: "before restart"})
+	if !it.Next() {
+		t.Fatalf("Next: %v", it.Err())
+	}
+	if got := it.Event().GetSummary(); got != "before restart" {
+		t.Fatalf("event %q, want %q", got, "before restart")
+	}
+
+	r.shutdown()
+	s.EventStreamer.Publish(&meshes.EventsResponse{Summary: "while down"})
+	r.start()
+	defer r.shutdown()
+
+	// The event published while the stream was broken is replayed, the next one is streamed.
+	for _, want := range []string{"while down", "after restart"} {
+		if want == "after restart" {
+			s.EventStreamer.Publish(&meshes.EventsResponse{Summary: want})
+		}
+		if !it.Next() {
+			t.Fatalf("Next: %v", it.Err())
+		}
+		if got := it.Event().GetSummary(); got != want {
+			t.Errorf("event %q, want %q", got, want)
+		}
+	}
+	if it.ReplayTruncated() {
+		t.Error("ReplayTruncated = true, but no event was missed")
+	}
+}
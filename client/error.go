@@ -0,0 +1,34 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"github.com/layer5io/meshkit/errors"
+)
+
+const (
+	ErrDialCode      = "1000"
+	ErrTLSConfigCode = "1001"
+)
+
+// ErrDial is returned when the connection to the adapter cannot be set up.
+func ErrDial(err error) error {
+	return errors.New(ErrDialCode, errors.Alert, []string{"Unable to connect to the adapter"}, []string{err.Error()}, []string{"The address of the adapter is invalid"}, []string{"Make sure the address has the form host:port"})
+}
+
+// ErrTLSConfig is returned when the TLS options of the client are invalid.
+func ErrTLSConfig(err error) error {
+	return errors.New(ErrTLSConfigCode, errors.Alert, []string{"Error during client TLS configuration"}, []string{err.Error()}, []string{"CA, certificate or key file is missing or invalid"}, []string{"Make sure the TLS files configured for the client exist and are PEM encoded"})
+}
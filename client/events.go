@@ -0,0 +1,208 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync/atomic"
+	"time"
+
+	backoff "github.com/cenkalti/backoff/v4"
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// EventFilter selects the events read by an EventIterator. Empty fields match all events.
+type EventFilter struct {
+	OperationIDs []string
+	EventTypes   []meshes.EventType
+	Components   []string
+	Usernames    []string
+	Meshes       []string
+
+	// FromSequence and Since request the replay of the recent events, from the given sequence number or time.
+	FromSequence uint64
+	Since        time.Time
+}
+
+// EventIterator reads the events streamed by the adapter:
+//
+//	it := c.Events(ctx, client.EventFilter{OperationIDs: []string{id}})
+//	defer it.Close()
+//	for it.Next() {
+//		e := it.Event()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+//
+// The iterator receives the events published from the moment Events returns, in addition to the events requested
+// for replay. If the stream breaks, e.g. because the adapter restarts or the iterator did not keep up with the events,
+// the iterator reconnects with backoff, and resumes after the last event read. Events missed in the meantime
+// are replayed, as far as the adapter still has them; otherwise ReplayTruncated reports it.
+type EventIterator struct {
+	client  *AdapterClient
+	ctx     context.Context
+	cancel  context.CancelFunc
+	req     *meshes.EventsRequest
+	backoff backoff.BackOff
+
+	stream    meshes.MeshService_StreamEventsClient
+	event     *meshes.EventsResponse
+	truncated bool
+	err       error
+	closed    atomic.Bool
+}
+
+// Events returns an iterator over the events matching filter. The iterator ends when ctx is done or Close is called.
+// The stream of events is opened before Events returns, so that the events of an operation applied next are not missed.
+// If the adapter cannot be reached, Next retries, and asks for the replay of the events published since Events was called.
+func (c *AdapterClient) Events(ctx context.Context, filter EventFilter) *EventIterator {
+	created := time.Now()
+	ctx, cancel := context.WithCancel(ctx)
+	req := &meshes.EventsRequest{
+		OperationIds:       filter.OperationIDs,
+		EventTypes:         filter.EventTypes,
+		Components:         filter.Components,
+		Usernames:          filter.Usernames,
+		Meshes:             filter.Meshes,
+		ReplayFromSequence: filter.FromSequence,
+	}
+	if !filter.Since.IsZero() {
+		req.ReplaySince = timestamppb.New(filter.Since)
+	}
+	it := &EventIterator{
+		client:  c,
+		ctx:     ctx,
+		cancel:  cancel,
+		req:     req,
+		backoff: backoff.WithContext(c.newBackOff(), ctx),
+	}
+	if err := it.connect(); err != nil && req.ReplayFromSequence == 0 && req.ReplaySince == nil {
+		req.ReplaySince = timestamppb.New(created)
+	}
+	return it
+}
+
+// connect opens the stream, and waits for its header, which the adapter sends once the stream is subscribed to the events.
+func (it *EventIterator) connect() error {
+	stream, err := it.client.client.StreamEvents(it.ctx, it.req)
+	if err != nil {
+		return err
+	}
+	md, err := stream.Header()
+	if err != nil {
+		return err
+	}
+	if v := md.Get(meshes.ReplayTruncatedHeader); len(v) != 0 && v[0] == "true" {
+		it.truncated = true
+	}
+	it.stream = stream
+	return nil
+}
+
+// Next waits for the next event, and reports whether there is one. Once it returns false, Err tells why.
+func (it *EventIterator) Next() bool {
+	for it.err == nil {
+		if it.stream == nil {
+			if err := it.connect(); err != nil {
+				it.retry(err)
+				continue
+			}
+		}
+
+		e, err := it.stream.Recv()
+		if err != nil {
+			it.stream = nil
+			it.retry(err)
+			continue
+		}
+		it.backoff.Reset()
+		// Sequence numbers start over when the adapter restarts.
+		if e.Sequence != 0 && e.Sequence < it.req.ReplayFromSequence && e.InstanceId == it.req.ReplayInstanceId {
+			continue
+		}
+		if e.Sequence != 0 {
+			it.req.ReplayFromSequence = e.Sequence + 1
+			it.req.ReplayInstanceId = e.InstanceId
+			it.req.ReplaySince = nil
+		}
+		it.event = e
+		return true
+	}
+	return false
+}
+
+// retry waits before the next attempt to read from the stream, or records err if the stream cannot be resumed.
+func (it *EventIterator) retry(err error) {
+	if it.ctx.Err() != nil {
+		it.err = it.ctx.Err()
+		return
+	}
+	// The stream ends with io.EOF when the adapter shuts down, and with codes.ResourceExhausted
+	// when the adapter disconnects subscribers that do not keep up.
+	if !errors.Is(err, io.EOF) {
+		switch status.Code(err) {
+		case codes.Unavailable, codes.ResourceExhausted:
+		default:
+			it.err = decodeError(err)
+			return
+		}
+	}
+
+	next := it.backoff.NextBackOff()
+	if next == backoff.Stop {
+		it.err = err
+		return
+	}
+	timer := time.NewTimer(next)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-it.ctx.Done():
+		it.err = it.ctx.Err()
+	}
+}
+
+// Event returns the event read by the last call to Next.
+func (it *EventIterator) Event() *meshes.EventsResponse {
+	return it.event
+}
+
+// ReplayTruncated reports whether some of the events requested for replay, or missed while the iterator reconnected,
+// could not be replayed because the adapter no longer had them, or had restarted in the meantime.
+func (it *EventIterator) ReplayTruncated() bool {
+	return it.truncated
+}
+
+// Err returns the error that ended the iteration, if any. It is the context's error if the iteration ended
+// because the context is done, and nil if it ended because Close was called.
+func (it *EventIterator) Err() error {
+	if it.closed.Load() && errors.Is(it.err, context.Canceled) {
+		return nil
+	}
+	return it.err
+}
+
+// Close ends the iteration, and releases the stream. It may be called concurrently with Next.
+func (it *EventIterator) Close() {
+	it.closed.Store(true)
+	it.cancel()
+}
@@ -0,0 +1,76 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	stderrors "errors"
+
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	"github.com/layer5io/meshkit/errors"
+	"google.golang.org/grpc/status"
+)
+
+// StatusError is returned for RPCs that failed with a MeshKit error, carried as ErrorDetails in the status.
+// It keeps the status, so that status.Code and status.FromError work on it, and unwraps to the MeshKit error.
+type StatusError struct {
+	Status     *status.Status
+	MeshKitErr *errors.Error
+}
+
+func (e *StatusError) Error() string {
+	return e.Status.Err().Error()
+}
+
+// GRPCStatus returns the status of the RPC.
+func (e *StatusError) GRPCStatus() *status.Status {
+	return e.Status
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.MeshKitErr
+}
+
+// MeshKitError returns the MeshKit error err is, or wraps, e.g. the error an RPC failed with on the adapter.
+func MeshKitError(err error) (*errors.Error, bool) {
+	var merr *errors.Error
+	if stderrors.As(err, &merr) {
+		return merr, true
+	}
+	return nil, false
+}
+
+// decodeError returns a StatusError if err is the status error of an RPC carrying ErrorDetails, and err otherwise.
+func decodeError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok || st == nil {
+		return err
+	}
+	for _, detail := range st.Details() {
+		if d, ok := detail.(*meshes.ErrorDetails); ok {
+			return &StatusError{
+				Status: st,
+				MeshKitErr: &errors.Error{
+					Code:                 d.GetCode(),
+					Severity:             errors.Severity(d.GetSeverity()),
+					ShortDescription:     d.GetShortDescription(),
+					LongDescription:      d.GetLongDescription(),
+					ProbableCause:        d.GetProbableCause(),
+					SuggestedRemediation: d.GetSuggestedRemediation(),
+				},
+			}
+		}
+	}
+	return err
+}
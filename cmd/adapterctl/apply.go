@@ -0,0 +1,241 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type applyFlags struct {
+	kubeconfigs []string
+	namespace   string
+	version     string
+	customBody  string
+	operationID string
+	username    string
+	mesh        string
+	values      []string
+	async       bool
+}
+
+// newApplyCommand returns the apply command, or the delete command, reverting operations, if del is set.
+func newApplyCommand(flags *globalFlags, del bool) *cobra.Command {
+	af := &applyFlags{}
+	cmd := &cobra.Command{
+		Use:   "apply OPERATION",
+		Short: "Apply an operation, and print its status once it has completed",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			op, err := af.operationRequest(args[0], del)
+			if err != nil {
+				return err
+			}
+			c, err := flags.newClient()
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+			ctx, cancel := flags.callContext(cmd)
+			defer cancel()
+
+			apply := c.Apply
+			if af.async {
+				apply = c.ApplyAsync
+			}
+			id, err := apply(ctx, op)
+			if err != nil {
+				return err
+			}
+			status, err := c.OperationStatus(ctx, id)
+			if grpcstatus.Code(err) == codes.Unimplemented {
+				// Adapters built on older versions of the library do not track operations.
+				result := "applied"
+				if af.async {
+					result = "accepted"
+				}
+				return printApplied(cmd.OutOrStdout(), flags.output, id, result)
+			}
+			if err != nil {
+				return err
+			}
+			return printStatus(cmd.OutOrStdout(), flags.output, status)
+		},
+	}
+	if del {
+		cmd.Use = "delete OPERATION"
+		cmd.Short = "Delete the resources created by an operation, and print its status once it has completed"
+	}
+
+	f := cmd.Flags()
+	f.StringArrayVar(&af.kubeconfigs, "kubeconfig", nil, "kubeconfig file of a cluster to apply the operation to, can be repeated")
+	f.StringVarP(&af.namespace, "namespace", "n", "default", "namespace to apply the operation to")
+	f.StringVar(&af.version, "version", "", "mesh version, defaults to the latest version")
+	f.StringVar(&af.customBody, "custom-body", "", "file with the manifest of a custom operation, - for stdin")
+	f.StringVar(&af.operationID, "operation-id", "", "ID of the operation, generated if not set")
+	f.StringVar(&af.username, "user", "", "user to apply the operation as")
+	f.StringVar(&af.mesh, "mesh", "", "mesh the operation is for, if the adapter hosts several meshes and the operation is not prefixed with it")
+	f.StringArrayVar(&af.values, "set", nil, "value of the templates of the operation, as key=value, can be repeated")
+	f.BoolVar(&af.async, "async", false, "return as soon as the adapter has accepted the operation")
+	return cmd
+}
+
+func (af *applyFlags) operationRequest(name string, del bool) (adapter.OperationRequest, error) {
+	kubeconfigs, err := readKubeconfigs(af.kubeconfigs)
+	if err != nil {
+		return adapter.OperationRequest{}, err
+	}
+	customBody := ""
+	if af.customBody != "" {
+		b, err := readFileOrStdin(af.customBody)
+		if err != nil {
+			return adapter.OperationRequest{}, err
+		}
+		customBody = string(b)
+	}
+	var values map[string]string
+	for _, kv := range af.values {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok || k == "" {
+			return adapter.OperationRequest{}, fmt.Errorf("invalid value %q, expected key=value", kv)
+		}
+		if values == nil {
+			values = make(map[string]string)
+		}
+		values[k] = v
+	}
+	return adapter.OperationRequest{
+		OperationName:     name,
+		Namespace:         af.namespace,
+		Username:          af.username,
+		CustomBody:        customBody,
+		IsDeleteOperation: del,
+		OperationID:       af.operationID,
+		K8sConfigs:        kubeconfigs,
+		Version:           af.version,
+		Mesh:              af.mesh,
+		Values:            values,
+	}, nil
+}
+
+func newStatusCommand(flags *globalFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status OPERATION_ID",
+		Short: "Print the status of an operation",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := flags.newClient()
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+			ctx, cancel := flags.callContext(cmd)
+			defer cancel()
+
+			status, err := c.OperationStatus(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			return printStatus(cmd.OutOrStdout(), flags.output, status)
+		},
+	}
+}
+
+func newCancelCommand(flags *globalFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "cancel OPERATION_ID",
+		Short: "Cancel a queued or running operation",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := flags.newClient()
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+			ctx, cancel := flags.callContext(cmd)
+			defer cancel()
+
+			return c.Cancel(ctx, args[0])
+		},
+	}
+}
+
+func printStatus(w io.Writer, output string, status *meshes.OperationStatusResponse) error {
+	if output == outputJSON {
+		return printJSON(w, status)
+	}
+	rows := [][]string{
+		{"operation_id", status.GetOperationId()},
+		{"operation", orNone(status.GetOperationName())},
+		{"state", status.GetState().String()},
+		{"started_at", formatTimestamp(status.GetStartedAt())},
+		{"ended_at", formatTimestamp(status.GetEndedAt())},
+		{"last_event", orNone(status.GetLastEvent().GetSummary())},
+		{"error", orNone(status.GetError())},
+	}
+	return printTable(w, []string{"FIELD", "VALUE"}, rows)
+}
+
+// printApplied prints the ID of an operation, and the result of ApplyOperation, if its status cannot be queried.
+func printApplied(w io.Writer, output, operationID, result string) error {
+	if output == outputJSON {
+		return printJSON(w, struct {
+			OperationID string `json:"operation_id"`
+			Result      string `json:"result"`
+		}{OperationID: operationID, Result: result})
+	}
+	rows := [][]string{
+		{"operation_id", operationID},
+		{"result", result},
+	}
+	return printTable(w, []string{"FIELD", "VALUE"}, rows)
+}
+
+func formatTimestamp(ts *timestamppb.Timestamp) string {
+	if ts == nil {
+		return "-"
+	}
+	return ts.AsTime().Local().Format(time.RFC3339)
+}
+
+// readKubeconfigs returns the contents of the kubeconfig files, as expected by the adapters.
+func readKubeconfigs(paths []string) ([]string, error) {
+	kubeconfigs := make([]string, 0, len(paths))
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read kubeconfig: %w", err)
+		}
+		kubeconfigs = append(kubeconfigs, string(b))
+	}
+	return kubeconfigs, nil
+}
+
+func readFileOrStdin(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
@@ -0,0 +1,176 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/spf13/cobra"
+)
+
+type bundleFlags struct {
+	operations string
+	version    string
+}
+
+func newBundleCommand(flags *globalFlags) *cobra.Command {
+	bf := &bundleFlags{}
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Build and verify bundles of the templates of the adapter's operations, for air-gapped installs",
+	}
+	cmd.PersistentFlags().StringVar(&bf.operations, "operations", "", "file with the operations as JSON, as in the adapter configuration, instead of the operations of the adapter, e.g. if it redacts template URLs")
+	cmd.AddCommand(newBundleBuildCommand(flags, bf), newBundleVerifyCommand(flags, bf))
+	return cmd
+}
+
+func newBundleBuildCommand(flags *globalFlags, bf *bundleFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "build OUTPUT",
+		Short: "Fetch the templates of the operations into a bundle, a .tar.gz or .tgz archive, or a directory otherwise",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ops, version, err := bf.load(cmd, flags)
+			if err != nil {
+				return err
+			}
+			if bf.version != "" {
+				version = bf.version
+			}
+			templates, err := adapter.BundleTemplates(ops)
+			if err != nil {
+				return err
+			}
+			resolver := &adapter.TemplateResolver{}
+
+			output := args[0]
+			if !strings.HasSuffix(output, ".tar.gz") && !strings.HasSuffix(output, ".tgz") {
+				err = adapter.BuildBundle(cmd.Context(), resolver, version, templates, output)
+			} else {
+				err = writeBundleArchive(cmd, resolver, version, templates, output)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Bundled %d template(s) into %s\n", len(templates), output)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&bf.version, "version", "", "version of the adapter the bundle is built for, defaults to the version of the adapter")
+	return cmd
+}
+
+func writeBundleArchive(cmd *cobra.Command, resolver *adapter.TemplateResolver, version string, templates []adapter.Template, output string) error {
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	if err := adapter.WriteBundleArchive(cmd.Context(), resolver, version, templates, f); err != nil {
+		_ = f.Close()
+		_ = os.Remove(output)
+		return err
+	}
+	return f.Close()
+}
+
+func newBundleVerifyCommand(flags *globalFlags, bf *bundleFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify BUNDLE",
+		Short: "Check that a bundle, an archive or a directory, holds all templates of the operations",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			b, err := adapter.OpenBundleFile(args[0])
+			if err != nil {
+				return err
+			}
+			ops, _, err := bf.load(cmd, flags)
+			if err != nil {
+				return err
+			}
+
+			templates, err := adapter.BundleTemplates(ops)
+			if err != nil {
+				return err
+			}
+			missingTemplates := b.Missing(templates)
+			missing := make(map[adapter.Template]bool)
+			for _, t := range missingTemplates {
+				missing[t] = true
+			}
+			if flags.output == outputJSON {
+				result := struct {
+					Version  string             `json:"version,omitempty"`
+					Created  string             `json:"created,omitempty"`
+					Missing  []adapter.Template `json:"missing"`
+					Complete bool               `json:"complete"`
+				}{Version: b.Version, Created: b.Created, Missing: missingTemplates, Complete: len(missing) == 0}
+				if err := printJSON(cmd.OutOrStdout(), result); err != nil {
+					return err
+				}
+			} else {
+				rows := make([][]string, 0, len(templates))
+				for _, t := range templates {
+					state := "bundled"
+					if missing[t] {
+						state = "missing"
+					}
+					rows = append(rows, []string{string(t), state})
+				}
+				if err := printTable(cmd.OutOrStdout(), []string{"TEMPLATE", "STATE"}, rows); err != nil {
+					return err
+				}
+			}
+			return b.Verify(ops)
+		},
+	}
+}
+
+// load returns the operations, read from the operations file if set, or from the adapter, and the version of the adapter
+// if they are read from it.
+func (bf *bundleFlags) load(cmd *cobra.Command, flags *globalFlags) (adapter.Operations, string, error) {
+	if bf.operations != "" {
+		b, err := readFileOrStdin(bf.operations)
+		if err != nil {
+			return nil, "", err
+		}
+		ops := make(adapter.Operations)
+		if err := json.Unmarshal(b, &ops); err != nil {
+			return nil, "", fmt.Errorf("unable to read operations: %w", err)
+		}
+		return ops, "", nil
+	}
+
+	c, err := flags.newClient()
+	if err != nil {
+		return nil, "", err
+	}
+	defer c.Close()
+	ctx, cancel := flags.callContext(cmd)
+	defer cancel()
+
+	ops, err := c.SupportedOperations(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	info, err := c.ComponentInfo(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	return ops, info.GetVersion(), nil
+}
@@ -0,0 +1,127 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/layer5io/meshery-adapter-library/client"
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+type eventsFlags struct {
+	operationIDs []string
+	eventTypes   []string
+	components   []string
+	usernames    []string
+	meshes       []string
+	fromSequence uint64
+	since        time.Duration
+}
+
+func newEventsCommand(flags *globalFlags) *cobra.Command {
+	ef := &eventsFlags{}
+	cmd := &cobra.Command{
+		Use:   "events",
+		Short: "Tail the events of the adapter, until interrupted",
+		Long: "Tail the events of the adapter, until interrupted. Filters of different kinds must all match, " +
+			"filters of the same kind match any of the values given. With --output json, events are printed one per line.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			filter, err := ef.eventFilter()
+			if err != nil {
+				return err
+			}
+			c, err := flags.newClient()
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+
+			it := c.Events(cmd.Context(), filter)
+			defer it.Close()
+			w := cmd.OutOrStdout()
+			if flags.output == outputTable {
+				fmt.Fprintf(w, "%-6s %-25s %-5s %-36s %s\n", "SEQ", "TIME", "TYPE", "OPERATION", "SUMMARY")
+			}
+			for it.Next() {
+				if err := printEvent(w, flags.output, it.Event()); err != nil {
+					return err
+				}
+			}
+			if cmd.Context().Err() != nil {
+				return nil
+			}
+			return it.Err()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringArrayVar(&ef.operationIDs, "operation-id", nil, "only events of this operation, can be repeated")
+	f.StringArrayVar(&ef.eventTypes, "type", nil, "only events of this type, info, warn or error, can be repeated")
+	f.StringArrayVar(&ef.components, "component", nil, "only events of this component, can be repeated")
+	f.StringArrayVar(&ef.usernames, "user", nil, "only events of operations applied by this user, can be repeated")
+	f.StringArrayVar(&ef.meshes, "mesh", nil, "only events of this mesh, if the adapter hosts several meshes, can be repeated")
+	f.Uint64Var(&ef.fromSequence, "from-sequence", 0, "replay the recent events from this sequence number first")
+	f.DurationVar(&ef.since, "since", 0, "replay the recent events of this last period first, e.g. 10m")
+	return cmd
+}
+
+func (ef *eventsFlags) eventFilter() (client.EventFilter, error) {
+	filter := client.EventFilter{
+		OperationIDs: ef.operationIDs,
+		Components:   ef.components,
+		Usernames:    ef.usernames,
+		Meshes:       ef.meshes,
+		FromSequence: ef.fromSequence,
+	}
+	for _, t := range ef.eventTypes {
+		value, ok := meshes.EventType_value[strings.ToUpper(t)]
+		if !ok {
+			return client.EventFilter{}, fmt.Errorf("invalid event type %q, must be info, warn or error", t)
+		}
+		filter.EventTypes = append(filter.EventTypes, meshes.EventType(value))
+	}
+	if ef.since > 0 {
+		filter.Since = time.Now().Add(-ef.since)
+	}
+	return filter, nil
+}
+
+func printEvent(w io.Writer, output string, e *meshes.EventsResponse) error {
+	if output == outputJSON {
+		b, err := protojson.Marshal(e)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	}
+	summary := e.GetSummary()
+	if e.GetCluster() != "" {
+		summary = "[" + e.GetCluster() + "] " + summary
+	}
+	if e.GetReplayTruncated() {
+		summary = "(earlier events missed) " + summary
+	}
+	_, err := fmt.Fprintf(w, "%-6d %-25s %-5s %-36s %s\n",
+		e.GetSequence(), formatTimestamp(e.GetTimestamp()), e.GetEventType(), orNone(e.GetOperationId()), summary)
+	return err
+}
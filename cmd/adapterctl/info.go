@@ -0,0 +1,111 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	"github.com/spf13/cobra"
+)
+
+func newOperationsCommand(flags *globalFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:     "operations",
+		Aliases: []string{"ops"},
+		Short:   "List the operations supported by the adapter",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			c, err := flags.newClient()
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+			ctx, cancel := flags.callContext(cmd)
+			defer cancel()
+
+			ops, err := c.SupportedOperations(ctx)
+			if err != nil {
+				return err
+			}
+			if flags.output == outputJSON {
+				return printJSON(cmd.OutOrStdout(), ops)
+			}
+
+			names := make([]string, 0, len(ops))
+			for name := range ops {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			rows := make([][]string, 0, len(names))
+			for _, name := range names {
+				op := ops[name]
+				versions := make([]string, 0, len(op.Versions))
+				for _, v := range op.Versions {
+					versions = append(versions, v.String())
+				}
+				rows = append(rows, []string{
+					name,
+					meshes.OpCategory(op.Type).String(),
+					orNone(strings.Join(versions, ",")),
+					orNone(op.Description),
+				})
+			}
+			return printTable(cmd.OutOrStdout(), []string{"NAME", "CATEGORY", "VERSIONS", "DESCRIPTION"}, rows)
+		},
+	}
+}
+
+func newInfoCommand(flags *globalFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "info",
+		Short: "Print the identity, build information and capabilities of the adapter",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			c, err := flags.newClient()
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+			ctx, cancel := flags.callContext(cmd)
+			defer cancel()
+
+			info, err := c.ComponentInfo(ctx)
+			if err != nil {
+				return err
+			}
+			if flags.output == outputJSON {
+				return printJSON(cmd.OutOrStdout(), info)
+			}
+
+			rows := [][]string{
+				{"type", orNone(info.GetType())},
+				{"name", orNone(info.GetName())},
+				{"version", orNone(info.GetVersion())},
+				{"git_sha", orNone(info.GetGitSha())},
+			}
+			keys := make([]string, 0, len(info.GetProperties()))
+			for key := range info.GetProperties() {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				rows = append(rows, []string{key, orNone(info.GetProperties()[key])})
+			}
+			return printTable(cmd.OutOrStdout(), []string{"PROPERTY", "VALUE"}, rows)
+		},
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command adapterctl drives any adapter built on the library through its MeshService, e.g. to debug it:
+//
+//	adapterctl --address localhost:10002 operations
+//	adapterctl apply istio_book_info_app --kubeconfig ~/.kube/config --namespace default
+//	adapterctl events --operation-id <id>
+//
+// Results are printed as tables, or as JSON with --output json.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/layer5io/meshery-adapter-library/client"
+	"github.com/spf13/cobra"
+)
+
+// globalFlags are the flags shared by all commands.
+type globalFlags struct {
+	address       string
+	output        string
+	timeout       time.Duration
+	tlsCAFile     string
+	tlsCertFile   string
+	tlsKeyFile    string
+	tlsServerName string
+	token         string
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := newRootCommand().ExecuteContext(ctx); err != nil {
+		printErrorDetails(err)
+		stop()
+		os.Exit(1)
+	}
+}
+
+// printErrorDetails prints the probable cause and the suggested remediation of the error an adapter returned, if any.
+func printErrorDetails(err error) {
+	merr, ok := client.MeshKitError(err)
+	if !ok {
+		return
+	}
+	if len(merr.ProbableCause) > 0 {
+		fmt.Fprintf(os.Stderr, "Probable cause: %s\n", strings.Join(merr.ProbableCause, ". "))
+	}
+	if len(merr.SuggestedRemediation) > 0 {
+		fmt.Fprintf(os.Stderr, "Suggested remediation: %s\n", strings.Join(merr.SuggestedRemediation, ". "))
+	}
+}
+
+func newRootCommand() *cobra.Command {
+	flags := &globalFlags{}
+	cmd := &cobra.Command{
+		Use:          "adapterctl",
+		Short:        "Drive a Meshery adapter through its gRPC MeshService",
+		SilenceUsage: true,
+		PersistentPreRunE: func(*cobra.Command, []string) error {
+			if flags.output != outputTable && flags.output != outputJSON {
+				return fmt.Errorf("invalid output format %q, must be %q or %q", flags.output, outputTable, outputJSON)
+			}
+			return nil
+		},
+	}
+
+	pf := cmd.PersistentFlags()
+	pf.StringVarP(&flags.address, "address", "a", "localhost:10000", "address of the adapter, host:port")
+	pf.StringVarP(&flags.output, "output", "o", outputTable, "output format, table or json")
+	pf.DurationVar(&flags.timeout, "timeout", 5*time.Minute, "timeout of calls to the adapter, not applying to events")
+	pf.StringVar(&flags.tlsCAFile, "tls-ca", "", "CA bundle to verify the adapter's certificate, enables TLS")
+	pf.StringVar(&flags.tlsCertFile, "tls-cert", "", "client certificate, for mutual TLS")
+	pf.StringVar(&flags.tlsKeyFile, "tls-key", "", "client key, for mutual TLS")
+	pf.StringVar(&flags.tlsServerName, "tls-server-name", "", "name to verify the adapter's certificate against")
+	pf.StringVar(&flags.token, "token", os.Getenv("ADAPTERCTL_TOKEN"), "bearer token, defaults to $ADAPTERCTL_TOKEN")
+
+	cmd.AddCommand(
+		newOperationsCommand(flags),
+		newApplyCommand(flags, false),
+		newApplyCommand(flags, true),
+		newStatusCommand(flags),
+		newCancelCommand(flags),
+		newOAMCommand(flags),
+		newEventsCommand(flags),
+		newInfoCommand(flags),
+		newBundleCommand(flags),
+	)
+	return cmd
+}
+
+// newClient connects to the adapter, as configured by the flags.
+func (f *globalFlags) newClient() (*client.AdapterClient, error) {
+	return client.New(f.address, client.Options{
+		TLSCAFile:     f.tlsCAFile,
+		TLSCertFile:   f.tlsCertFile,
+		TLSKeyFile:    f.tlsKeyFile,
+		TLSServerName: f.tlsServerName,
+		BearerToken:   f.token,
+	})
+}
+
+// callContext returns the context for a call to the adapter, bounded by the timeout flag.
+func (f *globalFlags) callContext(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(cmd.Context(), f.timeout)
+}
@@ -0,0 +1,104 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/spf13/cobra"
+)
+
+type oamFlags struct {
+	kubeconfigs []string
+	config      string
+	operationID string
+	username    string
+	mesh        string
+	del         bool
+}
+
+func newOAMCommand(flags *globalFlags) *cobra.Command {
+	of := &oamFlags{}
+	cmd := &cobra.Command{
+		Use:   "oam COMPONENT_FILE...",
+		Short: "Submit OAM components and configuration to the adapter",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req, err := of.oamRequest(args)
+			if err != nil {
+				return err
+			}
+			c, err := flags.newClient()
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+			ctx, cancel := flags.callContext(cmd)
+			defer cancel()
+
+			msg, err := c.ProcessOAM(ctx, req)
+			if err != nil {
+				return err
+			}
+			if flags.output == outputJSON {
+				return printJSON(cmd.OutOrStdout(), map[string]string{"message": msg})
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), msg)
+			return err
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringArrayVar(&of.kubeconfigs, "kubeconfig", nil, "kubeconfig file of a cluster to apply the components to, can be repeated")
+	f.StringVar(&of.config, "config", "", "file with the OAM application configuration, - for stdin")
+	f.StringVar(&of.operationID, "operation-id", "", "ID of the operation, allows to query its status and to cancel it")
+	f.StringVar(&of.username, "user", "", "user to apply the components as")
+	f.StringVar(&of.mesh, "mesh", "", "mesh the components are for, if the adapter hosts several meshes")
+	f.BoolVar(&of.del, "delete", false, "delete the components instead of applying them")
+	return cmd
+}
+
+func (of *oamFlags) oamRequest(componentFiles []string) (adapter.OAMRequest, error) {
+	kubeconfigs, err := readKubeconfigs(of.kubeconfigs)
+	if err != nil {
+		return adapter.OAMRequest{}, err
+	}
+	comps := make([]string, 0, len(componentFiles))
+	for _, path := range componentFiles {
+		b, err := readFileOrStdin(path)
+		if err != nil {
+			return adapter.OAMRequest{}, err
+		}
+		comps = append(comps, string(b))
+	}
+	config := ""
+	if of.config != "" {
+		b, err := readFileOrStdin(of.config)
+		if err != nil {
+			return adapter.OAMRequest{}, err
+		}
+		config = string(b)
+	}
+	return adapter.OAMRequest{
+		Username:    of.username,
+		DeleteOp:    of.del,
+		OamComps:    comps,
+		OamConfig:   config,
+		K8sConfigs:  kubeconfigs,
+		OperationID: of.operationID,
+		Mesh:        of.mesh,
+	}, nil
+}
@@ -33,6 +33,12 @@ var (
 		"port":     "10000",
 		"traceurl": "none",
 		"version":  "v0.1.0",
+
+		// TLS is disabled unless a certificate and key are provided.
+		"tlscertfile":     "",
+		"tlskeyfile":      "",
+		"tlsclientcafile": "",
+		"tlsclientauth":   "",
 	}
 
 	defaultMeshSpec = map[string]string{
@@ -26,6 +26,8 @@ const (
 	MeshService_StreamEvents_FullMethodName        = "/meshes.MeshService/StreamEvents"
 	MeshService_ProcessOAM_FullMethodName          = "/meshes.MeshService/ProcessOAM"
 	MeshService_ComponentInfo_FullMethodName       = "/meshes.MeshService/ComponentInfo"
+	MeshService_GetOperationStatus_FullMethodName  = "/meshes.MeshService/GetOperationStatus"
+	MeshService_CancelOperation_FullMethodName     = "/meshes.MeshService/CancelOperation"
 )
 
 // MeshServiceClient is the client API for MeshService service.
@@ -39,6 +41,8 @@ type MeshServiceClient interface {
 	StreamEvents(ctx context.Context, in *EventsRequest, opts ...grpc.CallOption) (MeshService_StreamEventsClient, error)
 	ProcessOAM(ctx context.Context, in *ProcessOAMRequest, opts ...grpc.CallOption) (*ProcessOAMResponse, error)
 	ComponentInfo(ctx context.Context, in *ComponentInfoRequest, opts ...grpc.CallOption) (*ComponentInfoResponse, error)
+	GetOperationStatus(ctx context.Context, in *OperationStatusRequest, opts ...grpc.CallOption) (*OperationStatusResponse, error)
+	CancelOperation(ctx context.Context, in *CancelOperationRequest, opts ...grpc.CallOption) (*CancelOperationResponse, error)
 }
 
 type meshServiceClient struct {
@@ -135,6 +139,24 @@ func (c *meshServiceClient) ComponentInfo(ctx context.Context, in *ComponentInfo
 	return out, nil
 }
 
+func (c *meshServiceClient) GetOperationStatus(ctx context.Context, in *OperationStatusRequest, opts ...grpc.CallOption) (*OperationStatusResponse, error) {
+	out := new(OperationStatusResponse)
+	err := c.cc.Invoke(ctx, MeshService_GetOperationStatus_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *meshServiceClient) CancelOperation(ctx context.Context, in *CancelOperationRequest, opts ...grpc.CallOption) (*CancelOperationResponse, error) {
+	out := new(CancelOperationResponse)
+	err := c.cc.Invoke(ctx, MeshService_CancelOperation_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MeshServiceServer is the server API for MeshService service.
 // All implementations must embed UnimplementedMeshServiceServer
 // for forward compatibility
@@ -146,6 +168,8 @@ type MeshServiceServer interface {
 	StreamEvents(*EventsRequest, MeshService_StreamEventsServer) error
 	ProcessOAM(context.Context, *ProcessOAMRequest) (*ProcessOAMResponse, error)
 	ComponentInfo(context.Context, *ComponentInfoRequest) (*ComponentInfoResponse, error)
+	GetOperationStatus(context.Context, *OperationStatusRequest) (*OperationStatusResponse, error)
+	CancelOperation(context.Context, *CancelOperationRequest) (*CancelOperationResponse, error)
 	mustEmbedUnimplementedMeshServiceServer()
 }
 
@@ -174,6 +198,12 @@ func (UnimplementedMeshServiceServer) ProcessOAM(context.Context, *ProcessOAMReq
 func (UnimplementedMeshServiceServer) ComponentInfo(context.Context, *ComponentInfoRequest) (*ComponentInfoResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ComponentInfo not implemented")
 }
+func (UnimplementedMeshServiceServer) GetOperationStatus(context.Context, *OperationStatusRequest) (*OperationStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOperationStatus not implemented")
+}
+func (UnimplementedMeshServiceServer) CancelOperation(context.Context, *CancelOperationRequest) (*CancelOperationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelOperation not implemented")
+}
 func (UnimplementedMeshServiceServer) mustEmbedUnimplementedMeshServiceServer() {}
 
 // UnsafeMeshServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -316,6 +346,42 @@ func _MeshService_ComponentInfo_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MeshService_GetOperationStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OperationStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MeshServiceServer).GetOperationStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MeshService_GetOperationStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MeshServiceServer).GetOperationStatus(ctx, req.(*OperationStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MeshService_CancelOperation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelOperationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MeshServiceServer).CancelOperation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MeshService_CancelOperation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MeshServiceServer).CancelOperation(ctx, req.(*CancelOperationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // MeshService_ServiceDesc is the grpc.ServiceDesc for MeshService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -347,6 +413,14 @@ var MeshService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ComponentInfo",
 			Handler:    _MeshService_ComponentInfo_Handler,
 		},
+		{
+			MethodName: "GetOperationStatus",
+			Handler:    _MeshService_GetOperationStatus_Handler,
+		},
+		{
+			MethodName: "CancelOperation",
+			Handler:    _MeshService_CancelOperation_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -0,0 +1,19 @@
+// Copyright Meshery Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package meshes
+
+// ReplayTruncatedHeader is the key of the header metadata sent by StreamEvents before any event, "true" if some of
+// the events requested for replay had already been evicted, and "false" otherwise.
+const ReplayTruncatedHeader = "meshery-replay-truncated"